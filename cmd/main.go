@@ -2,20 +2,27 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 
 	"translatego/internal/app"
+	"translatego/internal/cli"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
 	ti := textinput.New()
 	ti.Placeholder = "Enter text to translate"
 	ti.CharLimit = 500
 	ti.Width = 50
 
 	application := app.NewApp()
+	defer application.Close()
 
 	model := application.GetModel()
 
@@ -24,6 +31,8 @@ func main() {
 	p := tea.NewProgram(model)
 
 	if _, err := p.Run(); err != nil {
+		// log.Fatal exits without running deferred calls.
+		application.Close()
 		log.Fatal(err)
 	}
 }
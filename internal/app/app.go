@@ -1,11 +1,16 @@
 package app
 
 import (
+	"time"
+
 	"translatego/internal/cache"
 	"translatego/internal/clipboard"
 	"translatego/internal/config"
+	"translatego/internal/core"
+	"translatego/internal/quota"
 	"translatego/internal/ratelimit"
 	"translatego/internal/utils"
+	"translatego/pkg/translate"
 
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -16,32 +21,32 @@ type App struct {
 	cache     *cache.Manager
 	clipboard *clipboard.Manager
 	rateLimit *ratelimit.Manager
+	quota     *quota.Tracker
 	config    *config.Manager
 	services  []utils.ServiceConfig
+	client    *translate.Client
+	core      *core.Core
 }
 
 func NewApp() *App {
-	configManager := config.NewManager()
-	if err := configManager.Initialize(); err != nil {
-		_ = err
-	}
-
-	var services []utils.ServiceConfig
-	if configServices := configManager.GetEnabledProviders(); len(configServices) > 0 {
-		services = configServices
-	} else {
-		services = config.GetAvailableServices()
-	}
+	c := core.Load()
 
 	return &App{
-		cache:     cache.NewManager(),
+		cache:     c.Cache,
 		clipboard: clipboard.NewManager(),
-		rateLimit: ratelimit.NewManager(),
-		config:    configManager,
-		services:  services,
+		rateLimit: c.RateLimit,
+		quota:     c.Quota,
+		config:    c.Config,
+		services:  c.Services,
+		client:    c.Client,
+		core:      c,
 	}
 }
 
+func (a *App) Close() error {
+	return a.core.Close()
+}
+
 func (a *App) GetModel() *Model {
 	languages := config.GetSupportedLanguages()
 
@@ -75,20 +80,29 @@ func (a *App) GetModel() *Model {
 		Width:               80,
 		Height:              24,
 		TargetLang:          "ru",
+		SourceLang:          translate.AutoDetect,
 		IsTranslating:       false,
 		TranslatingCount:    0,
-		TranslationCache:    make(map[string]map[string]string),
-		RateLimits:          make(map[string]*RateLimiter),
 		RetryAttempts:       make(map[string]int),
-		MaxRetries:          3,
+		QueuedUntil:         make(map[string]time.Time),
+		CircuitUntil:        make(map[string]time.Time),
+		Suspects:            make(map[string]string),
+		QuotaRemaining:      make(map[string]string),
+		RetryLimits:         make(map[string]int),
+		RetryPolicies:       a.core.Retry,
 		TranslationProgress: make(map[string]float64),
 		Viewports:           make(map[string]viewport.Model),
 		Columns:             2,
 		Progress:            progress.New(progress.WithScaledGradient("#000000", "#FFFFFF")),
 		CheckProgress:       0.0,
 		StatusMessage:       "",
+		Strategy:            a.client.Strategy(),
 		app:                 a,
 	}
 
+	if a.core.CacheErr != nil {
+		model.StatusMessage = "⚠️  " + a.core.CacheErr.Error()
+	}
+
 	return model
 }
@@ -0,0 +1,316 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"translatego/pkg/translate"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const browserPageSize = 10
+
+// BrowserModel lists either the cache or, once Tab switched to it, the
+// translation memory.
+type BrowserModel struct {
+	Search   *textinput.Model
+	Memory   bool
+	Entries  []translate.CacheEntry
+	Segments []translate.Segment
+	Selected int
+	Message  string
+}
+
+func (m *Model) openCacheBrowser() {
+	if m.Browser.Search == nil {
+		ti := textinput.New()
+		ti.Placeholder = "Search source, translation, provider or language"
+		ti.CharLimit = 200
+		ti.Width = 50
+		m.Browser.Search = &ti
+	}
+	m.Browser.Search.SetValue("")
+	m.Browser.Search.Focus()
+	m.Browser.Memory = m.app.cache == nil
+	m.Browser.Selected = 0
+	m.Browser.Message = ""
+	m.refreshCacheBrowser()
+
+	if m.TextInput != nil {
+		m.TextInput.Blur()
+	}
+	m.State = CacheState
+}
+
+func (m *Model) closeCacheBrowser() {
+	m.State = MainState
+	if m.TextInput != nil {
+		m.TextInput.Focus()
+	}
+}
+
+func (m *Model) toggleBrowserView() {
+	if m.app.cache == nil {
+		m.Browser.Message = "Cache is disabled (settings.cache_enabled)"
+		return
+	}
+	m.Browser.Memory = !m.Browser.Memory
+	m.Browser.Selected = 0
+	m.Browser.Message = ""
+	m.refreshCacheBrowser()
+}
+
+func (m *Model) refreshCacheBrowser() {
+	query := strings.ToLower(strings.TrimSpace(m.Browser.Search.Value()))
+	matches := func(fields ...string) bool {
+		if query == "" {
+			return true
+		}
+		for _, field := range fields {
+			if strings.Contains(strings.ToLower(field), query) {
+				return true
+			}
+		}
+		return false
+	}
+
+	m.Browser.Entries, m.Browser.Segments = nil, nil
+	if m.Browser.Memory {
+		for _, seg := range m.app.client.Memory().Segments() {
+			if matches(seg.Source, seg.Target, seg.Provider, seg.SourceLang, seg.TargetLang) {
+				m.Browser.Segments = append(m.Browser.Segments, seg)
+			}
+		}
+	} else {
+		for _, entry := range m.app.cache.Entries() {
+			if matches(entry.Text, entry.Translation, entry.Provider, entry.Source, entry.Target) {
+				m.Browser.Entries = append(m.Browser.Entries, entry)
+			}
+		}
+	}
+
+	if m.Browser.Selected >= m.browserLen() {
+		m.Browser.Selected = m.browserLen() - 1
+	}
+	if m.Browser.Selected < 0 {
+		m.Browser.Selected = 0
+	}
+}
+
+func (m *Model) browserLen() int {
+	if m.Browser.Memory {
+		return len(m.Browser.Segments)
+	}
+	return len(m.Browser.Entries)
+}
+
+func (m *Model) selectedCacheEntry() (translate.CacheEntry, bool) {
+	if m.Browser.Memory || m.Browser.Selected < 0 || m.Browser.Selected >= len(m.Browser.Entries) {
+		return translate.CacheEntry{}, false
+	}
+	return m.Browser.Entries[m.Browser.Selected], true
+}
+
+func (m *Model) selectedSegment() (translate.Segment, bool) {
+	if !m.Browser.Memory || m.Browser.Selected < 0 || m.Browser.Selected >= len(m.Browser.Segments) {
+		return translate.Segment{}, false
+	}
+	return m.Browser.Segments[m.Browser.Selected], true
+}
+
+// retranslate translates text again between the given languages, with the
+// named provider or with all of them when provider is empty, and closes the
+// browser. The session's languages stay as they are. It reports whether the
+// translation started.
+func (m *Model) retranslate(provider, text, source, target string, cmds *[]tea.Cmd) bool {
+	services := m.AvailableServices
+	if provider != "" {
+		services = nil
+		for _, svc := range m.AvailableServices {
+			if svc.Name == provider {
+				services = append(services, svc)
+			}
+		}
+		if len(services) == 0 {
+			m.Browser.Message = fmt.Sprintf("%s is not available", provider)
+			return false
+		}
+	}
+
+	if !m.translateText(text, source, target, services, cmds) {
+		m.Browser.Message = fmt.Sprintf("%q is already being translated", truncate(text, 30))
+		return false
+	}
+
+	m.closeCacheBrowser()
+	if m.TextInput != nil {
+		m.TextInput.SetValue(text)
+	}
+	return true
+}
+
+// updateCacheBrowser handles key presses while the browser is open; all other
+// messages keep flowing to the main screen so running translations complete.
+func (m *Model) updateCacheBrowser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.closeCacheBrowser()
+		return m, nil
+	case "tab":
+		m.toggleBrowserView()
+		return m, nil
+	case "up":
+		if m.Browser.Selected > 0 {
+			m.Browser.Selected--
+		}
+		return m, nil
+	case "down":
+		if m.Browser.Selected < m.browserLen()-1 {
+			m.Browser.Selected++
+		}
+		return m, nil
+	case "pgup":
+		m.Browser.Selected = max(m.Browser.Selected-browserPageSize, 0)
+		return m, nil
+	case "pgdown":
+		m.Browser.Selected = max(min(m.Browser.Selected+browserPageSize, m.browserLen()-1), 0)
+		return m, nil
+	case "ctrl+d":
+		if entry, ok := m.selectedCacheEntry(); ok {
+			m.app.client.Forget(entry.Provider, entry.Text, entry.Source, entry.Target)
+			m.Browser.Message = fmt.Sprintf("Deleted %s entry for %q", entry.Provider, truncate(entry.Text, 30))
+			m.refreshCacheBrowser()
+		}
+		if seg, ok := m.selectedSegment(); ok {
+			if _, err := m.app.client.Memory().Delete(seg); err != nil {
+				m.Browser.Message = err.Error()
+			} else {
+				m.Browser.Message = fmt.Sprintf("Deleted segment for %q", truncate(seg.Source, 30))
+			}
+			m.refreshCacheBrowser()
+		}
+		return m, nil
+	case "ctrl+y":
+		translation := ""
+		if entry, ok := m.selectedCacheEntry(); ok {
+			translation = entry.Translation
+		}
+		if seg, ok := m.selectedSegment(); ok {
+			translation = seg.Target
+		}
+		if translation != "" {
+			if err := m.app.clipboard.CopyToClipboard(translation); err != nil {
+				m.Browser.Message = err.Error()
+			} else {
+				m.Browser.Message = "Copied translation to clipboard"
+			}
+		}
+		return m, nil
+	case "ctrl+r":
+		if entry, ok := m.selectedCacheEntry(); ok {
+			// The requests read the cache only once the commands run, after
+			// this returns, so the old entry is dropped just in time.
+			if m.retranslate(entry.Provider, entry.Text, entry.Source, entry.Target, &cmds) {
+				m.app.client.Forget(entry.Provider, entry.Text, entry.Source, entry.Target)
+			}
+			return m, tea.Batch(cmds...)
+		}
+		if seg, ok := m.selectedSegment(); ok {
+			m.retranslate(seg.Provider, seg.Source, seg.SourceLang, seg.TargetLang, &cmds)
+			return m, tea.Batch(cmds...)
+		}
+		return m, nil
+	}
+
+	newModel, cmd := m.Browser.Search.Update(msg)
+	*m.Browser.Search = newModel
+	m.refreshCacheBrowser()
+
+	return m, cmd
+}
+
+func (m *Model) CacheView() string {
+	var content strings.Builder
+
+	if m.Browser.Memory {
+		content.WriteString(TitleStyle.Render("📚 Translation memory") + "\n")
+		content.WriteString(fmt.Sprintf("%d segments\n\n", m.app.client.Memory().Size()))
+	} else {
+		stats := m.app.cache.Stats()
+		content.WriteString(TitleStyle.Render("🗂  Translation cache") + "\n")
+		content.WriteString(fmt.Sprintf("%d entries • %d hits • %d misses • %d evictions\n\n",
+			stats.Entries, stats.Hits, stats.Misses, stats.Evictions))
+	}
+
+	content.WriteString(InputStyle.Width(m.Width-4).Render("Search:\n"+m.Browser.Search.View()) + "\n\n")
+
+	count := m.browserLen()
+	if count == 0 && m.Browser.Memory {
+		content.WriteString("No translation memory segments.\n")
+	} else if count == 0 {
+		content.WriteString("No cached translations.\n")
+	} else {
+		page := m.Browser.Selected / browserPageSize
+		pages := (count + browserPageSize - 1) / browserPageSize
+		start := page * browserPageSize
+		end := min(start+browserPageSize, count)
+
+		width := max(m.Width-30, 20)
+		for i := start; i < end; i++ {
+			cursor := "  "
+			if i == m.Browser.Selected {
+				cursor = "▶ "
+			}
+			if m.Browser.Memory {
+				seg := m.Browser.Segments[i]
+				content.WriteString(fmt.Sprintf("%s%-10s %s→%s %6s  %s\n",
+					cursor, seg.Provider, seg.SourceLang, seg.TargetLang, formatAge(time.Since(seg.Created)),
+					truncate(seg.Source, width/2)))
+				content.WriteString(fmt.Sprintf("    %s\n", truncate(seg.Target, width)))
+				continue
+			}
+			entry := m.Browser.Entries[i]
+			content.WriteString(fmt.Sprintf("%s%-10s %s→%s %6s  %s\n",
+				cursor, entry.Provider, entry.Source, entry.Target, formatAge(time.Since(entry.Created)),
+				truncate(entry.Text, width/2)))
+			content.WriteString(fmt.Sprintf("    %s\n", truncate(entry.Translation, width)))
+		}
+		content.WriteString(fmt.Sprintf("\nPage %d/%d\n", page+1, pages))
+	}
+
+	if m.Browser.Message != "" {
+		content.WriteString("\n" + m.Browser.Message + "\n")
+	}
+
+	content.WriteString("\n" + InstructionStyle.Render("Tab cache/memory • ↑↓ select • PgUp/PgDn page • Ctrl+D delete • Ctrl+R re-translate • Ctrl+Y copy • Esc back"))
+
+	return content.String()
+}
+
+func formatAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return "now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	}
+}
+
+func truncate(text string, width int) string {
+	runes := []rune(strings.ReplaceAll(text, "\n", " "))
+	if width <= 1 || len(runes) <= width {
+		return string(runes)
+	}
+	return string(runes[:width-1]) + "…"
+}
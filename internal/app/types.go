@@ -1,11 +1,17 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	"slices"
 	"strings"
 	"time"
 
+	"translatego/internal/retry"
 	"translatego/internal/utils"
+	"translatego/pkg/translate"
 
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -23,6 +29,7 @@ const (
 	CheckingState
 	MainState
 	ConfigState
+	CacheState
 )
 
 type SpinnerState int
@@ -36,6 +43,14 @@ const (
 type SetupModel struct {
 	SelectedIndex int
 	Languages     []string
+	SourceStep    bool // Choosing the source language after the target
+	SourceIndex   int  // Index into SourceLanguages
+}
+
+// SourceLanguages lists the choices for the source language, auto-detection
+// first.
+func (s SetupModel) SourceLanguages() []string {
+	return append([]string{translate.AutoDetect}, s.Languages...)
 }
 
 type ConfigModel struct {
@@ -50,6 +65,7 @@ type Model struct {
 	State               AppState
 	Setup               SetupModel
 	Config              ConfigModel
+	Browser             BrowserModel
 	TextInput           *textinput.Model
 	AvailableServices   []utils.ServiceConfig
 	Translations        map[string]string
@@ -60,12 +76,12 @@ type Model struct {
 	Width               int
 	Height              int
 	TargetLang          string
+	SourceLang          string // translate.AutoDetect or a language code
 	IsTranslating       bool
 	TranslatingCount    int
-	TranslationCache    map[string]map[string]string
-	RateLimits          map[string]*RateLimiter
 	RetryAttempts       map[string]int
-	MaxRetries          int
+	RetryLimits         map[string]int // Maximum attempts of the retry in progress
+	RetryPolicies       retry.Policies
 	TranslationProgress map[string]float64
 	Viewports           map[string]viewport.Model
 	Columns             int
@@ -73,85 +89,60 @@ type Model struct {
 	CheckProgress       float64
 	StatusMessage       string
 	CurrentText         string // Store current text being translated
+	CurrentSource       string // Languages CurrentText is translated between,
+	CurrentTarget       string // which the browser may set apart from the session's
+	Generation          int    // Bumped on every new or cancelled translation
+	Strategy            translate.Strategy
+	TMMatches           []translate.MemoryMatch
+	QueuedUntil         map[string]time.Time // Providers waiting for their rate limiter
+	CircuitUntil        map[string]time.Time // Providers whose circuit breaker is open
+	Suspects            map[string]string    // Why a provider's translation looks untranslated
+	QuotaRemaining      map[string]string    // Quota left per provider, refreshed after translations
+	translationCtx      context.Context
+	cancelTranslation   context.CancelFunc
 	app                 *App
 }
 
 type ResultMsg utils.Result
 
 type TranslationMsg struct {
-	Service string
-	Text    string
-	Err     error
-}
-
-type RetryMsg struct {
-	Service string
-	Text    string
-	Source  string
-	Target  string
-	Attempt int
-	Delay   time.Duration
+	Service    string
+	Text       string
+	Warning    string
+	Suspect    string
+	Err        error
+	Generation int
 }
 
-type RateLimiter struct {
-	Requests    int
-	LastReset   time.Time
-	MaxRequests int
-	Window      time.Duration
-}
-
-func NewRateLimiter(maxRequests int, window time.Duration) *RateLimiter {
-	return &RateLimiter{
-		Requests:    0,
-		LastReset:   time.Now(),
-		MaxRequests: maxRequests,
-		Window:      window,
-	}
-}
-
-func (rl *RateLimiter) Allow() bool {
-	now := time.Now()
-	if now.Sub(rl.LastReset) > rl.Window {
-		rl.Requests = 0
-		rl.LastReset = now
-	}
-	return rl.Requests < rl.MaxRequests
+// QueuedMsg reports that a provider is waiting for its rate limiter; next
+// keeps listening for the rest of the translation.
+type QueuedMsg struct {
+	Service    string
+	Start      time.Time
+	Generation int
+	next       tea.Cmd
 }
 
-func (rl *RateLimiter) RecordRequest() {
-	rl.Requests++
+type queuedNotice struct {
+	service string
+	start   time.Time
 }
 
-type CacheManager struct {
-	cache map[string]map[string]string
+type StrategyResultMsg struct {
+	Results    []translate.Result
+	Text       string
+	Err        error
+	Generation int
 }
 
-func NewCacheManager() *CacheManager {
-	return &CacheManager{
-		cache: make(map[string]map[string]string),
-	}
-}
-
-func (cm *CacheManager) GetCacheKey(text, source, target string) string {
-	return text + "|" + source + "|" + target
-}
-
-func (cm *CacheManager) Get(service, text, source, target string) (string, bool) {
-	if serviceCache, exists := cm.cache[service]; exists {
-		cacheKey := cm.GetCacheKey(text, source, target)
-		if translation, cached := serviceCache[cacheKey]; cached {
-			return translation, true
-		}
-	}
-	return "", false
-}
-
-func (cm *CacheManager) Set(service, text, source, target, translation string) {
-	if _, exists := cm.cache[service]; !exists {
-		cm.cache[service] = make(map[string]string)
-	}
-	cacheKey := cm.GetCacheKey(text, source, target)
-	cm.cache[service][cacheKey] = translation
+type RetryMsg struct {
+	Service    string
+	Text       string
+	Source     string
+	Target     string
+	Attempt    int
+	Delay      time.Duration
+	Generation int
 }
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -168,15 +159,30 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "ctrl+c", "q":
 				return m, tea.Quit
 			case "enter":
-				m.TargetLang = m.Setup.Languages[m.Setup.SelectedIndex]
+				if !m.Setup.SourceStep {
+					m.TargetLang = m.Setup.Languages[m.Setup.SelectedIndex]
+					m.Setup.SourceStep = true
+					return m, nil
+				}
+				m.SourceLang = m.Setup.SourceLanguages()[m.Setup.SourceIndex]
 				m.State = LoadingState
 				return m, m.Init()
+			case "esc":
+				m.Setup.SourceStep = false
 			case "up", "k":
-				if m.Setup.SelectedIndex > 0 {
+				if m.Setup.SourceStep {
+					if m.Setup.SourceIndex > 0 {
+						m.Setup.SourceIndex--
+					}
+				} else if m.Setup.SelectedIndex > 0 {
 					m.Setup.SelectedIndex--
 				}
 			case "down", "j":
-				if m.Setup.SelectedIndex < len(m.Setup.Languages)-1 {
+				if m.Setup.SourceStep {
+					if m.Setup.SourceIndex < len(m.Setup.SourceLanguages())-1 {
+						m.Setup.SourceIndex++
+					}
+				} else if m.Setup.SelectedIndex < len(m.Setup.Languages)-1 {
 					m.Setup.SelectedIndex++
 				}
 			}
@@ -277,6 +283,10 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(cmds...)
 	}
 
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.State == CacheState {
+		return m.updateCacheBrowser(keyMsg)
+	}
+
 	if m.State == LoadingState {
 		switch msg.(type) {
 		case struct{}:
@@ -390,14 +400,29 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.CheckedCount == len(m.app.services) {
 			m.Done = true
 			m.State = MainState
+			m.refreshQuota()
 			if m.TextInput != nil {
 				m.TextInput.Focus()
 			}
 		}
 	case TranslationMsg:
+		if msg.Generation != m.Generation {
+			break
+		}
 		if retryCmd := m.handleTranslationResult(msg); retryCmd != nil {
 			cmds = append(cmds, *retryCmd)
 		}
+		m.refreshQuota()
+	case StrategyResultMsg:
+		if msg.Generation == m.Generation {
+			m.handleStrategyResult(msg)
+			m.refreshQuota()
+		}
+	case QueuedMsg:
+		if msg.Generation == m.Generation {
+			m.QueuedUntil[msg.Service] = msg.Start
+			cmds = append(cmds, msg.next)
+		}
 	case RetryMsg:
 		m.handleRetry(msg, &cmds)
 	case spinner.TickMsg:
@@ -436,7 +461,7 @@ func (m *Model) Init() tea.Cmd {
 			cfg := svc
 			index := i
 			cmds = append(cmds, tea.Tick(time.Duration(index)*300*time.Millisecond, func(t time.Time) tea.Msg {
-				return ResultMsg(utils.CheckService(cfg))
+				return ResultMsg(utils.CheckService(context.Background(), cfg))
 			}))
 		}
 		return tea.Batch(cmds...)
@@ -446,11 +471,15 @@ func (m *Model) Init() tea.Cmd {
 }
 
 func (m *Model) handleTranslationResult(msg TranslationMsg) *tea.Cmd {
+	delete(m.QueuedUntil, msg.Service)
 	if msg.Err == nil {
 		m.Translations[msg.Service] = msg.Text
+		m.noteSuspect(msg.Service, msg.Suspect)
+		if msg.Warning != "" {
+			m.StatusMessage = "⚠️  " + msg.Warning
+		}
 		delete(m.RetryAttempts, msg.Service)
 		m.TranslationProgress[msg.Service] = 1.0
-		m.app.cache.Set(msg.Service, "", "", "", msg.Text)
 	} else {
 		return m.handleTranslationError(msg)
 	}
@@ -465,14 +494,20 @@ func (m *Model) handleTranslationError(msg TranslationMsg) *tea.Cmd {
 	attempts := m.RetryAttempts[msg.Service]
 
 	var isRetryable bool
-	if serviceErr, ok := msg.Err.(*utils.ServiceError); ok {
+	var retryAfter time.Duration
+	if serviceErr, ok := translate.AsServiceError(msg.Err); ok {
 		isRetryable = serviceErr.IsRetryable
+		retryAfter = serviceErr.RetryAfter
 	} else {
 		isRetryable = true
 	}
 
-	if attempts < m.MaxRetries && isRetryable {
+	errorType := utils.CreateServiceError(msg.Service, msg.Err, 0).ErrorType
+	policy := m.RetryPolicies.For(msg.Service, errorType)
+
+	if attempts < policy.MaxAttempts && isRetryable {
 		m.RetryAttempts[msg.Service] = attempts + 1
+		m.RetryLimits[msg.Service] = policy.MaxAttempts
 
 		retryText := m.getRetryText(msg.Service, attempts+1)
 		m.Translations[msg.Service] = retryText
@@ -484,21 +519,23 @@ func (m *Model) handleTranslationError(msg TranslationMsg) *tea.Cmd {
 			m.SpinnerStates[msg.Service] = SpinnerRetrying
 		}
 
-		delay := time.Duration(attempts+1) * 2 * time.Second
+		delay := max(policy.Delay(attempts+1), retryAfter)
 		retryCmd := tea.Tick(delay, func(t time.Time) tea.Msg {
 			return RetryMsg{
-				Service: msg.Service,
-				Text:    m.CurrentText,
-				Source:  "",
-				Target:  m.TargetLang,
-				Attempt: attempts + 1,
-				Delay:   delay,
+				Service:    msg.Service,
+				Text:       m.CurrentText,
+				Source:     m.CurrentSource,
+				Target:     m.CurrentTarget,
+				Attempt:    attempts + 1,
+				Delay:      delay,
+				Generation: msg.Generation,
 			}
 		})
 		return &retryCmd
 	} else {
 		detailedError := utils.GetServiceSpecificErrorMessage(msg.Service, msg.Err, 0)
 		m.Translations[msg.Service] = detailedError
+		m.noteCircuit(msg.Service, msg.Err)
 		delete(m.RetryAttempts, msg.Service)
 		m.TranslationProgress[msg.Service] = 0.0
 
@@ -507,10 +544,33 @@ func (m *Model) handleTranslationError(msg TranslationMsg) *tea.Cmd {
 			m.Spinners[msg.Service] = sp
 			m.SpinnerStates[msg.Service] = SpinnerError
 		}
+
+		m.TranslatingCount--
+		if m.TranslatingCount <= 0 {
+			m.IsTranslating = false
+		}
 	}
 	return nil
 }
 
+// noteCircuit remembers when the provider's open circuit lets requests
+// through again, so that its box can count down.
+func (m *Model) noteCircuit(service string, err error) {
+	if serviceErr, ok := translate.AsServiceError(err); ok && serviceErr.ErrorType == translate.ErrorTypeCircuitOpen {
+		m.CircuitUntil[service] = time.Now().Add(serviceErr.RetryAfter)
+		return
+	}
+	delete(m.CircuitUntil, service)
+}
+
+func (m *Model) noteSuspect(service, suspect string) {
+	if suspect == "" {
+		delete(m.Suspects, service)
+		return
+	}
+	m.Suspects[service] = suspect
+}
+
 func (m *Model) getRetryText(service string, attempt int) string {
 	dots := ""
 	dotCount := (time.Now().Unix() % 4)
@@ -521,146 +581,236 @@ func (m *Model) getRetryText(service string, attempt int) string {
 		dots += " "
 	}
 
-	return fmt.Sprintf("🔄 Retrying%s (attempt %d/%d)", dots, attempt, m.MaxRetries)
+	return fmt.Sprintf("🔄 Retrying%s (attempt %d/%d)", dots, attempt, m.RetryLimits[service])
 }
 
 func (m *Model) createTranslationCommand(svc utils.ServiceConfig, text, targetLang string) tea.Cmd {
-	return tea.Cmd(func() tea.Msg {
-		cfg := svc
-		source := utils.DetectFromLanguage(text)
-		target := utils.DetectToLanguage(source, targetLang)
-
-		if cached, exists := m.app.cache.Get(cfg.Name, text, source, target); exists {
-			return TranslationMsg{Service: cfg.Name, Text: cached, Err: nil}
-		}
-
-		if !m.app.rateLimit.Allow(cfg.Name) {
-			rateLimitErr := &utils.ServiceError{
-				Service:     cfg.Name,
-				ErrorType:   utils.ErrorTypeRateLimit,
-				Message:     "Rate limit exceeded",
-				Suggestion:  "Wait before making more requests",
-				IsRetryable: true,
-			}
-			return TranslationMsg{Service: cfg.Name, Text: "", Err: rateLimitErr}
-		}
-
-		if cfg.Name == "OPENAI" && m.app.config.IsAPIKeyRequired("OPENAI") {
-			apiKey := m.app.config.GetAPIKey("OPENAI")
-			if apiKey != "" {
-
-				newHeaders := make(map[string]string)
-				for k, v := range cfg.Headers {
-					if k == "Authorization" {
-						newHeaders[k] = "Bearer " + apiKey
-					} else {
-						newHeaders[k] = v
-					}
-				}
-				cfg.Headers = newHeaders
-			}
-		}
-
-		if cfg.Name == "OPENROUTER" && m.app.config.IsAPIKeyRequired("OPENROUTER") {
-			apiKey := m.app.config.GetAPIKey("OPENROUTER")
-			if apiKey != "" {
-
-				newHeaders := make(map[string]string)
-				for k, v := range cfg.Headers {
-					if k == "Authorization" {
-						newHeaders[k] = "Bearer " + apiKey
-					} else {
-						newHeaders[k] = v
-					}
-				}
-				cfg.Headers = newHeaders
-			}
-		}
-
-		trans, err := utils.TranslateService(cfg, text, source, target)
-		if err == nil {
-			m.app.cache.Set(cfg.Name, text, source, target, trans)
-			m.app.rateLimit.RecordRequest(cfg.Name)
-		}
-
-		return TranslationMsg{Service: cfg.Name, Text: trans, Err: err}
+	return m.translateCommand(svc.Name, translate.Request{
+		Text:      text,
+		Source:    m.CurrentSource,
+		Target:    targetLang,
+		Providers: []string{svc.Name},
 	})
 }
 
 func (m *Model) handleRetry(msg RetryMsg, cmds *[]tea.Cmd) {
+	if msg.Generation != m.Generation {
+		return
+	}
 
-	cfg := utils.ServiceConfig{}
+	found := false
 	for _, svc := range m.AvailableServices {
 		if svc.Name == msg.Service {
-			cfg = svc
+			found = true
 			break
 		}
 	}
 
-	if cfg.Name == "" {
+	if !found {
 		return
 	}
 
-	cmd := func() tea.Msg {
-		source := utils.DetectFromLanguage(msg.Text)
-		target := utils.DetectToLanguage(source, msg.Target)
+	*cmds = append(*cmds, m.translateCommand(msg.Service, translate.Request{
+		Text:      msg.Text,
+		Source:    msg.Source,
+		Target:    msg.Target,
+		Providers: []string{msg.Service},
+	}))
+}
 
-		finalCfg := cfg
-		if cfg.Name == "OPENAI" && m.app.config.IsAPIKeyRequired("OPENAI") {
-			apiKey := m.app.config.GetAPIKey("OPENAI")
-			if apiKey != "" {
-				if finalCfg.Headers == nil {
-					finalCfg.Headers = make(map[string]string)
-				}
-				newHeaders := make(map[string]string)
-				for k, v := range finalCfg.Headers {
-					if k == "Authorization" {
-						newHeaders[k] = "Bearer " + apiKey
-					} else {
-						newHeaders[k] = v
-					}
-				}
-				finalCfg.Headers = newHeaders
+func (m *Model) translateCommand(service string, req translate.Request) tea.Cmd {
+	client := m.app.client
+	ctx := m.translationContext()
+	generation := m.Generation
+	return func() tea.Msg {
+		queued := make(chan queuedNotice, 1)
+		done := make(chan tea.Msg, 1)
+		req.OnQueued = notifyQueued(queued)
+
+		go func() {
+			resp, _ := client.Translate(ctx, req)
+			if len(resp.Results) == 0 {
+				done <- TranslationMsg{Service: service, Err: fmt.Errorf("no result from %s", service), Generation: generation}
+				return
 			}
-		}
 
-		if cfg.Name == "OPENROUTER" && m.app.config.IsAPIKeyRequired("OPENROUTER") {
-			apiKey := m.app.config.GetAPIKey("OPENROUTER")
-			if apiKey != "" {
-				if finalCfg.Headers == nil {
-					finalCfg.Headers = make(map[string]string)
-				}
-				newHeaders := make(map[string]string)
-				for k, v := range finalCfg.Headers {
-					if k == "Authorization" {
-						newHeaders[k] = "Bearer " + apiKey
-					} else {
-						newHeaders[k] = v
-					}
-				}
-				finalCfg.Headers = newHeaders
+			result := resp.Results[0]
+			done <- TranslationMsg{Service: service, Text: result.Text, Warning: result.Warning, Suspect: result.Suspect, Err: result.Err, Generation: generation}
+		}()
+
+		return waitForTranslation(generation, queued, done)()
+	}
+}
+
+// waitForTranslation delivers queue notices as they come and the final result
+// once the translation is done.
+func waitForTranslation(generation int, queued <-chan queuedNotice, done <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case msg := <-done:
+			return msg
+		case notice := <-queued:
+			return QueuedMsg{
+				Service:    notice.service,
+				Start:      notice.start,
+				Generation: generation,
+				next:       waitForTranslation(generation, queued, done),
 			}
 		}
+	}
+}
+
+func notifyQueued(queued chan<- queuedNotice) func(provider string, start time.Time) {
+	return func(provider string, start time.Time) {
+		select {
+		case queued <- queuedNotice{service: provider, start: start}:
+		default:
+		}
+	}
+}
 
-		if !m.app.rateLimit.Allow(cfg.Name) {
-			rateLimitErr := &utils.ServiceError{
-				Service:     cfg.Name,
-				ErrorType:   utils.ErrorTypeRateLimit,
-				Message:     "Rate limit exceeded",
-				Suggestion:  "Wait before making more requests",
-				IsRetryable: true,
+func (m *Model) strategyCommand(services []utils.ServiceConfig, text string) tea.Cmd {
+	names := make([]string, 0, len(services))
+	for _, svc := range services {
+		names = append(names, svc.Name)
+	}
+
+	client := m.app.client
+	ctx := m.translationContext()
+	generation := m.Generation
+	req := translate.Request{
+		Text:      text,
+		Source:    m.CurrentSource,
+		Target:    m.CurrentTarget,
+		Providers: names,
+		Strategy:  m.Strategy,
+	}
+	return func() tea.Msg {
+		queued := make(chan queuedNotice, len(names))
+		done := make(chan tea.Msg, 1)
+		req.OnQueued = notifyQueued(queued)
+
+		go func() {
+			resp, err := client.Translate(ctx, req)
+			done <- StrategyResultMsg{Results: resp.Results, Text: resp.Text, Err: err, Generation: generation}
+		}()
+
+		return waitForTranslation(generation, queued, done)()
+	}
+}
+
+func (m *Model) handleStrategyResult(msg StrategyResultMsg) {
+	m.QueuedUntil = make(map[string]time.Time)
+	var warning string
+	for _, result := range msg.Results {
+		switch {
+		case result.Err == nil:
+			m.Translations[result.Provider] = result.Text
+			m.noteSuspect(result.Provider, result.Suspect)
+			m.TranslationProgress[result.Provider] = 1.0
+			if result.Warning != "" {
+				warning = "⚠️  " + result.Warning
 			}
-			return TranslationMsg{Service: cfg.Name, Text: "", Err: rateLimitErr}
+		case errors.Is(result.Err, translate.ErrSkipped):
+			m.Translations[result.Provider] = fmt.Sprintf("⏭ Skipped (%s strategy)", m.Strategy)
+			m.TranslationProgress[result.Provider] = 0.0
+		default:
+			m.Translations[result.Provider] = utils.GetServiceSpecificErrorMessage(result.Provider, result.Err, 0)
+			m.noteCircuit(result.Provider, result.Err)
+			m.TranslationProgress[result.Provider] = 0.0
+			if sp, exists := m.Spinners[result.Provider]; exists {
+				sp.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+				m.Spinners[result.Provider] = sp
+				m.SpinnerStates[result.Provider] = SpinnerError
+			}
+		}
+	}
+
+	if errors.Is(msg.Err, translate.ErrNoQuorum) {
+		m.StatusMessage = "Providers did not agree on a translation"
+	} else {
+		m.StatusMessage = warning
+	}
+
+	m.IsTranslating = false
+	m.TranslatingCount = 0
+}
+
+// refreshQuota reads the quota left for every provider. View does not read
+// the quota file itself, as it runs on every spinner tick.
+func (m *Model) refreshQuota() {
+	for _, svc := range m.AvailableServices {
+		m.QuotaRemaining[svc.Name] = m.app.quota.Status(svc.Name).Remaining()
+	}
+}
+
+// cycleStrategy switches to the next strategy and saves it as the default
+// for later runs.
+func (m *Model) cycleStrategy() {
+	next := translate.StrategyAll
+	strategies := translate.Strategies()
+	for i, strategy := range strategies {
+		if strategy == m.Strategy {
+			next = strategies[(i+1)%len(strategies)]
+			break
 		}
+	}
+	m.Strategy = next
+
+	if err := m.app.config.SetStrategy(string(next)); err != nil {
+		m.StatusMessage = fmt.Sprintf("⚠️  Failed to save strategy: %v", err)
+	}
+}
 
-		trans, err := utils.TranslateService(finalCfg, msg.Text, source, target)
-		if err == nil {
-			m.app.cache.Set(cfg.Name, msg.Text, source, target, trans)
-			m.app.rateLimit.RecordRequest(cfg.Name)
+func (m *Model) cycleSourceLang() {
+	languages := m.Setup.SourceLanguages()
+	for i, language := range languages {
+		if language == m.SourceLang {
+			m.SourceLang = languages[(i+1)%len(languages)]
+			return
 		}
-		return TranslationMsg{Service: cfg.Name, Text: trans, Err: err}
 	}
-	*cmds = append(*cmds, cmd)
+	m.SourceLang = translate.AutoDetect
+}
+
+func (m *Model) translationContext() context.Context {
+	if m.translationCtx == nil {
+		return context.Background()
+	}
+	return m.translationCtx
+}
+
+func (m *Model) startTranslation() {
+	m.stopTranslation()
+	m.translationCtx, m.cancelTranslation = context.WithCancel(context.Background())
+}
+
+func (m *Model) stopTranslation() {
+	if m.cancelTranslation != nil {
+		m.cancelTranslation()
+	}
+	m.translationCtx = nil
+	m.cancelTranslation = nil
+	m.Generation++
+	m.QueuedUntil = make(map[string]time.Time)
+}
+
+func (m *Model) cancelCurrentTranslation() {
+	if !m.IsTranslating {
+		return
+	}
+
+	m.stopTranslation()
+	for _, svc := range m.AvailableServices {
+		if m.Translations[svc.Name] == "" || m.SpinnerStates[svc.Name] == SpinnerRetrying {
+			m.Translations[svc.Name] = "⏹ Cancelled"
+			m.SpinnerStates[svc.Name] = SpinnerLoading
+			m.TranslationProgress[svc.Name] = 0.0
+			delete(m.RetryAttempts, svc.Name)
+		}
+	}
+	m.IsTranslating = false
+	m.TranslatingCount = 0
 }
 
 func (m *Model) handleKeyPress(msg tea.KeyMsg, cmds *[]tea.Cmd) {
@@ -687,7 +837,15 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg, cmds *[]tea.Cmd) {
 		m.copyTranslationToClipboard(3)
 	case "alt+5":
 		m.cycleLayout()
+	case "alt+s":
+		m.cycleStrategy()
+	case "alt+f":
+		m.cycleSourceLang()
+	case "alt+b":
+		m.openCacheBrowser()
 	case "alt+c":
+	case "esc":
+		m.cancelCurrentTranslation()
 	case "enter":
 		m.handleEnterKey(cmds)
 	}
@@ -751,55 +909,87 @@ func (m *Model) handleEnterKey(cmds *[]tea.Cmd) {
 	if m.TextInput == nil {
 		return
 	}
+	m.translateText(m.TextInput.Value(), m.SourceLang, m.TargetLang, m.AvailableServices, cmds)
+}
 
-	text := m.TextInput.Value()
-	if !m.IsTranslating && text != "" {
-		m.CurrentText = text
-		m.IsTranslating = true
-		m.TranslatingCount = len(m.AvailableServices)
-
-		var validServices []utils.ServiceConfig
-		for _, svc := range m.AvailableServices {
-			if svc.Name == "OPENAI" && m.app.config.IsAPIKeyRequired("OPENAI") {
-				apiKey := m.app.config.GetAPIKey("OPENAI")
-				if apiKey == "" {
-					m.TranslatingCount--
-					m.Translations[svc.Name] = "⚠️ OpenAI API key required"
-					m.TranslationProgress[svc.Name] = 0.0
-					continue
-				}
+// translateText starts translating text with services and reports whether it
+// did; text that is already being translated is not started again. Available
+// services left out are marked as not asked.
+func (m *Model) translateText(text, source, target string, services []utils.ServiceConfig, cmds *[]tea.Cmd) bool {
+	if text == "" || m.IsTranslating && text == m.CurrentText {
+		return false
+	}
+
+	m.startTranslation()
+	m.RetryAttempts = make(map[string]int)
+	m.CurrentText = text
+	m.CurrentSource, m.CurrentTarget = source, target
+	m.TMMatches = m.app.client.Suggest(translate.Request{Text: text, Source: source, Target: target}, 3)
+	m.IsTranslating = true
+	m.TranslatingCount = len(services)
+
+	for _, svc := range m.AvailableServices {
+		if !slices.ContainsFunc(services, func(asked utils.ServiceConfig) bool { return asked.Name == svc.Name }) {
+			m.Translations[svc.Name] = "⏭ Not asked"
+			delete(m.Suspects, svc.Name)
+			m.TranslationProgress[svc.Name] = 0.0
+		}
+	}
+
+	var validServices []utils.ServiceConfig
+	for _, svc := range services {
+		if svc.Name == "OPENAI" && m.app.config.IsAPIKeyRequired("OPENAI") {
+			apiKey := m.app.config.GetAPIKey("OPENAI")
+			if apiKey == "" {
+				m.TranslatingCount--
+				m.Translations[svc.Name] = "⚠️ OpenAI API key required"
+				m.TranslationProgress[svc.Name] = 0.0
+				continue
 			}
-			if svc.Name == "OPENROUTER" && m.app.config.IsAPIKeyRequired("OPENROUTER") {
-				apiKey := m.app.config.GetAPIKey("OPENROUTER")
-				if apiKey == "" {
-					m.TranslatingCount--
-					m.Translations[svc.Name] = "⚠️ OpenRouter API key required"
-					m.TranslationProgress[svc.Name] = 0.0
-					continue
-				}
+		}
+		if svc.Name == "OPENROUTER" && m.app.config.IsAPIKeyRequired("OPENROUTER") {
+			apiKey := m.app.config.GetAPIKey("OPENROUTER")
+			if apiKey == "" {
+				m.TranslatingCount--
+				m.Translations[svc.Name] = "⚠️ OpenRouter API key required"
+				m.TranslationProgress[svc.Name] = 0.0
+				continue
 			}
-			validServices = append(validServices, svc)
 		}
+		if status := m.app.quota.Status(svc.Name); status.Exhausted() {
+			m.TranslatingCount--
+			m.Translations[svc.Name] = fmt.Sprintf("📉 Quota used up (%s)", status.Summary())
+			m.TranslationProgress[svc.Name] = 0.0
+			continue
+		}
+		validServices = append(validServices, svc)
+	}
 
-		m.TranslatingCount = len(validServices)
+	m.TranslatingCount = len(validServices)
 
-		for _, svc := range validServices {
-			m.Translations[svc.Name] = ""
-			m.TranslationProgress[svc.Name] = 0.0
-			sp := spinner.New()
-			sp.Spinner = spinner.Dot
-			sp.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
-			m.Spinners[svc.Name] = sp
-			m.SpinnerStates[svc.Name] = SpinnerLoading
+	for _, svc := range validServices {
+		m.Translations[svc.Name] = ""
+		delete(m.Suspects, svc.Name)
+		m.TranslationProgress[svc.Name] = 0.0
+		sp := spinner.New()
+		sp.Spinner = spinner.Dot
+		sp.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+		m.Spinners[svc.Name] = sp
+		m.SpinnerStates[svc.Name] = SpinnerLoading
 
-			*cmds = append(*cmds, sp.Tick)
-		}
+		*cmds = append(*cmds, sp.Tick)
+	}
 
-		for _, svc := range validServices {
-			translationCmd := m.createTranslationCommand(svc, text, m.TargetLang)
-			*cmds = append(*cmds, translationCmd)
-		}
+	if m.Strategy.SingleResult() && len(validServices) > 0 {
+		*cmds = append(*cmds, m.strategyCommand(validServices, text))
+		return true
 	}
+
+	for _, svc := range validServices {
+		translationCmd := m.createTranslationCommand(svc, text, m.CurrentTarget)
+		*cmds = append(*cmds, translationCmd)
+	}
+	return true
 }
 
 func (m *Model) ConfigView() string {
@@ -868,6 +1058,10 @@ func (m *Model) View() string {
 		return m.ConfigView()
 	}
 
+	if m.State == CacheState {
+		return m.CacheView()
+	}
+
 	if m.State == LoadingState {
 		return "\n\n🌍 Preparing Translatego...\n\n"
 	}
@@ -905,9 +1099,15 @@ func (m *Model) View() string {
 		cols = 3
 	}
 
+	tmBox := m.TMView(m.Width - 4)
+	reservedHeight := 10
+	if tmBox != "" {
+		reservedHeight += lipgloss.Height(tmBox)
+	}
+
 	rows := (numServices + cols - 1) / cols
 	boxWidth := (m.Width - 2) / cols
-	boxHeight := (m.Height - 10) / rows
+	boxHeight := (m.Height - reservedHeight) / rows
 
 	minBoxWidth := 40
 	minBoxHeight := 8
@@ -919,7 +1119,7 @@ func (m *Model) View() string {
 			cols = 1
 		}
 		rows = (numServices + cols - 1) / cols
-		boxHeight = (m.Height - 10) / rows
+		boxHeight = (m.Height - reservedHeight) / rows
 	}
 	if boxHeight < minBoxHeight {
 		boxHeight = minBoxHeight
@@ -929,11 +1129,16 @@ func (m *Model) View() string {
 		trans := m.Translations[svc.Name]
 		if trans == "" && m.IsTranslating {
 			trans = m.Spinners[svc.Name].View() + " Translating..."
+			if wait := time.Until(m.QueuedUntil[svc.Name]); wait > 0 {
+				trans = fmt.Sprintf("⏳ Rate limited, starting in %ds", int(math.Ceil(wait.Seconds())))
+			}
 		} else if trans == "" {
 			trans = "Ready for translation"
 		} else if m.SpinnerStates[svc.Name] == SpinnerRetrying {
 			attempts := m.RetryAttempts[svc.Name]
 			trans = m.getRetryText(svc.Name, attempts)
+		} else if wait := time.Until(m.CircuitUntil[svc.Name]); wait > 0 && m.SpinnerStates[svc.Name] == SpinnerError {
+			trans = fmt.Sprintf("🔌 Circuit open, retry in %ds", int(math.Ceil(wait.Seconds())))
 		}
 
 		progress := m.TranslationProgress[svc.Name]
@@ -942,6 +1147,10 @@ func (m *Model) View() string {
 			progressBar = "\n" + CreateProgressBar(progress, boxWidth-6)
 		}
 
+		if suspect := m.Suspects[svc.Name]; suspect != "" && m.SpinnerStates[svc.Name] != SpinnerRetrying {
+			trans = "⚠️  " + suspect + "\n" + trans
+		}
+
 		wrappedTrans := WrapText(trans, boxWidth-6)
 
 		boxStyle := BoxStyle.
@@ -949,7 +1158,11 @@ func (m *Model) View() string {
 			Height(boxHeight - 3)
 
 		displayContent := wrappedTrans + progressBar
-		box := boxStyle.Render(fmt.Sprintf("[%s]\n%s", svc.Name, displayContent))
+		title := svc.Name
+		if remaining := m.QuotaRemaining[svc.Name]; remaining != "" {
+			title += " · " + remaining
+		}
+		box := boxStyle.Render(fmt.Sprintf("[%s]\n%s", title, displayContent))
 		translationBoxes = append(translationBoxes, box)
 	}
 
@@ -964,9 +1177,17 @@ func (m *Model) View() string {
 	}
 	translationsView := lipgloss.JoinVertical(lipgloss.Left, gridRows...)
 
-	layout := lipgloss.JoinVertical(lipgloss.Left, inputBox, translationsView)
+	sections := []string{inputBox}
+	if tmBox != "" {
+		sections = append(sections, tmBox)
+	}
+	sections = append(sections, translationsView)
+	layout := lipgloss.JoinVertical(lipgloss.Left, sections...)
 
-	help := fmt.Sprintf("\nPress Enter to translate | Target language: %s | Ctrl+V paste | Ctrl+L clear | Alt+1/2/3 copy | q to quit.", m.TargetLang)
+	help := fmt.Sprintf("\nPress Enter to translate | From: %s (Alt+F) | Target language: %s | Strategy: %s (Alt+S) | Ctrl+V paste | Ctrl+L clear | Esc cancel | Alt+1/2/3 copy | Alt+B cache | q to quit.", m.SourceLang, m.TargetLang, m.Strategy)
+	if m.StatusMessage != "" {
+		help = "\n" + m.StatusMessage + help
+	}
 
 	return layout + help
 }
@@ -983,6 +1204,11 @@ var (
 			Padding(1, 1).
 			Align(lipgloss.Left)
 
+	TMStyle = lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("178")).
+		Padding(0, 1)
+
 	TitleStyle = lipgloss.NewStyle().
 			Bold(true).
 			Foreground(lipgloss.Color("39"))
@@ -5,6 +5,9 @@ import (
 	"strings"
 
 	"github.com/common-nighthawk/go-figure"
+
+	"translatego/internal/languages"
+	"translatego/pkg/translate"
 )
 
 func (m *Model) SetupView() string {
@@ -28,37 +31,57 @@ func (m *Model) SetupView() string {
 
 	finalArt := strings.Join(borderedLines, "\n")
 
-	langNames := map[string]string{
-		"ru": "Русский",
-		"en": "English",
-		"de": "Deutsch",
-		"fr": "Français",
-		"es": "Español",
-		"it": "Italiano",
-		"ja": "日本語",
-		"zh": "中文",
-		"ko": "한국어",
-		"ar": "العربية",
+	title := "Choose your target language:"
+	options, selected := m.Setup.Languages, m.Setup.SelectedIndex
+	help := "Use ↑↓ arrows to navigate • Press Enter to select • Press Ctrl+C to quit"
+	if m.Setup.SourceStep {
+		title = fmt.Sprintf("Translate into %s from:", languages.NativeName(m.TargetLang))
+		options, selected = m.Setup.SourceLanguages(), m.Setup.SourceIndex
+		help = "Use ↑↓ arrows to navigate • Press Enter to select • Esc to go back • Press Ctrl+C to quit"
 	}
 
+	// Scroll the list with the cursor when it is taller than the screen.
+	visible := max(m.Height-len(borderedLines)-6, 5)
+	first := min(max(selected-visible/2, 0), max(len(options)-visible, 0))
+	last := min(first+visible, len(options))
+
 	var menuItems []string
 
-	for i, lang := range m.Setup.Languages {
+	for i := first; i < last; i++ {
+		lang := options[i]
 		cursor := "  "
-		if i == m.Setup.SelectedIndex {
+		if i == selected {
 			cursor = "▶ "
 		}
-		name := langNames[lang]
+		name := languages.NativeName(lang)
+		if lang == translate.AutoDetect {
+			name = "Auto-detect"
+		}
 		menuItems = append(menuItems, fmt.Sprintf("%s%s (%s)", cursor, name, lang))
 	}
 
 	menu := strings.Join(menuItems, "\n")
 
-	instructions := "\n\n" + InstructionStyle.
-		Render("Use ↑↓ arrows to navigate • Press Enter to select • Press Ctrl+C to quit")
+	instructions := "\n\n" + InstructionStyle.Render(help)
+
+	return finalArt + "\n\n" + TitleStyle.Render(title) + "\n\n" + menu + instructions
+}
+
+func (m *Model) TMView(width int) string {
+	if len(m.TMMatches) == 0 {
+		return ""
+	}
+
+	lines := []string{"[TM]"}
+	for _, match := range m.TMMatches {
+		line := fmt.Sprintf("%3d%%  %s → %s", int(match.Score*100), match.Source, match.Target)
+		if match.Provider != "" {
+			line += fmt.Sprintf(" (%s)", match.Provider)
+		}
+		lines = append(lines, WrapText(line, width-4))
+	}
 
-	return finalArt + "\n\n" + TitleStyle.
-		Render("Choose your target language:") + "\n\n" + menu + instructions
+	return TMStyle.Width(width).Render(strings.Join(lines, "\n"))
 }
 
 func CreateProgressBar(progress float64, width int) string {
@@ -0,0 +1,258 @@
+package breaker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"translatego/internal/state"
+)
+
+const stateFileName = "breaker.json"
+
+type State string
+
+const (
+	Closed   State = "closed"
+	Open     State = "open"
+	HalfOpen State = "half-open"
+)
+
+// Options configure when a circuit opens and for how long. Only failures of
+// the listed error types count; others, such as a bad API key, say nothing
+// about whether the provider is up.
+type Options struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+	ProbeTimeout     time.Duration
+	ErrorTypes       map[string]bool
+}
+
+func DefaultOptions() Options {
+	return Options{
+		FailureThreshold: 5,
+		Cooldown:         time.Minute,
+		ProbeTimeout:     30 * time.Second,
+		ErrorTypes: map[string]bool{
+			"TIMEOUT":          true,
+			"NETWORK_ERROR":    true,
+			"SERVER_ERROR":     true,
+			"SERVICE_DOWN":     true,
+			"NOT_FOUND":        true,
+			"INVALID_RESPONSE": true,
+		},
+	}
+}
+
+type circuit struct {
+	Failures  int       `json:"failures"`
+	OpenUntil time.Time `json:"open_until,omitzero"`
+	Probing   time.Time `json:"probing,omitzero"`
+}
+
+// Breaker keeps one circuit per provider. A circuit opens after
+// FailureThreshold consecutive failures and rejects requests for Cooldown.
+// It then lets a single probe through (half-open): success closes the
+// circuit, failure opens it again.
+type Breaker struct {
+	options  Options
+	circuits map[string]*circuit
+	path     string
+	mu       sync.Mutex
+}
+
+func New(options Options) *Breaker {
+	return &Breaker{
+		options:  options,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// NewShared returns a breaker whose circuits live in a state file in dir, so
+// that every translatego process sees a provider as down at once.
+func NewShared(dir string, options Options) (*Breaker, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	b := New(options)
+	b.path = filepath.Join(dir, stateFileName)
+	return b, nil
+}
+
+// Allow reports whether a request to provider may go ahead and, if not, how
+// long until the circuit lets the next probe through.
+func (b *Breaker) Allow(provider string) (bool, time.Duration) {
+	var allowed bool
+	var retryIn time.Duration
+
+	// Only a probe changes the circuit; everything else is a lookup.
+	var current State
+	b.view(func() {
+		c := b.circuit(provider)
+		now := time.Now()
+
+		current = b.state(c, now)
+		if current == Open {
+			retryIn = c.OpenUntil.Sub(now)
+		}
+	})
+	if current != HalfOpen {
+		return current == Closed, retryIn
+	}
+
+	b.update(func() {
+		c := b.circuit(provider)
+		now := time.Now()
+
+		switch b.state(c, now) {
+		case Closed:
+			allowed = true
+		case Open:
+			retryIn = c.OpenUntil.Sub(now)
+		case HalfOpen:
+			if now.Before(c.Probing) {
+				retryIn = c.Probing.Sub(now)
+				return
+			}
+			c.Probing = now.Add(b.options.ProbeTimeout)
+			allowed = true
+		}
+	})
+
+	return allowed, retryIn
+}
+
+func (b *Breaker) Success(provider string) {
+	clean := false
+	b.view(func() {
+		clean = *b.circuit(provider) == circuit{}
+	})
+	if clean {
+		return
+	}
+
+	b.update(func() {
+		c := b.circuit(provider)
+		*c = circuit{}
+	})
+}
+
+// Release gives up a probe that Allow granted but that was never sent, so the
+// next request can probe instead of waiting for ProbeTimeout.
+func (b *Breaker) Release(provider string) {
+	probing := false
+	b.view(func() {
+		c := b.circuit(provider)
+		probing = b.state(c, time.Now()) == HalfOpen && !c.Probing.IsZero()
+	})
+	if !probing {
+		return
+	}
+
+	b.update(func() {
+		c := b.circuit(provider)
+		if b.state(c, time.Now()) == HalfOpen {
+			c.Probing = time.Time{}
+		}
+	})
+}
+
+// Failure records a failed request and reports whether it counted. Error
+// types the breaker does not track leave the circuit as it is, so a probe
+// ending in one of them still has to be released.
+func (b *Breaker) Failure(provider, errorType string) bool {
+	if !b.options.ErrorTypes[errorType] {
+		return false
+	}
+
+	b.update(func() {
+		c := b.circuit(provider)
+		now := time.Now()
+
+		c.Failures++
+		if b.state(c, now) == HalfOpen || c.Failures >= b.options.FailureThreshold {
+			c.OpenUntil = now.Add(b.options.Cooldown)
+			c.Probing = time.Time{}
+		}
+	})
+	return true
+}
+
+// State returns the provider's circuit state and, unless it is closed, how
+// long until the next probe.
+func (b *Breaker) State(provider string) (State, time.Duration) {
+	var current State
+	var retryIn time.Duration
+
+	b.view(func() {
+		c := b.circuit(provider)
+		now := time.Now()
+
+		current = b.state(c, now)
+		switch {
+		case current == Open:
+			retryIn = c.OpenUntil.Sub(now)
+		case current == HalfOpen && now.Before(c.Probing):
+			retryIn = c.Probing.Sub(now)
+		}
+	})
+
+	return current, retryIn
+}
+
+func (b *Breaker) state(c *circuit, now time.Time) State {
+	switch {
+	case c.OpenUntil.IsZero():
+		return Closed
+	case now.Before(c.OpenUntil):
+		return Open
+	default:
+		return HalfOpen
+	}
+}
+
+func (b *Breaker) circuit(provider string) *circuit {
+	c, exists := b.circuits[provider]
+	if !exists {
+		c = &circuit{}
+		b.circuits[provider] = c
+	}
+	return c
+}
+
+// update runs fn against the shared circuits when there is a state file, and
+// against the in-process ones otherwise or when the file is unusable.
+func (b *Breaker) update(fn func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.path == "" {
+		fn()
+		return
+	}
+
+	circuits := make(map[string]*circuit)
+	if ran, err := state.Update(b.path, &circuits, func() {
+		b.circuits = circuits
+		fn()
+	}); err != nil && !ran {
+		fn()
+	}
+}
+
+// view is update for fn that only reads the circuits.
+func (b *Breaker) view(fn func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.path != "" {
+		circuits := make(map[string]*circuit)
+		if err := state.Load(b.path, &circuits); err == nil {
+			b.circuits = circuits
+		}
+	}
+	fn()
+}
@@ -0,0 +1,251 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"translatego/internal/state"
+)
+
+const logFileName = "cache.log"
+
+// record is one line of the append-only cache log. A record with Deleted set
+// removes the key; later records win over earlier ones.
+type record struct {
+	Service     string `json:"s"`
+	Key         string `json:"k"`
+	Text        string `json:"x,omitempty"`
+	Source      string `json:"sl,omitempty"`
+	Target      string `json:"tl,omitempty"`
+	Translation string `json:"v,omitempty"`
+	Time        int64  `json:"t"`
+	Deleted     bool   `json:"d,omitempty"`
+}
+
+type diskLog struct {
+	path string
+	file *os.File
+	mu   sync.Mutex
+}
+
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return filepath.Join(dir, "translatego"), nil
+}
+
+// openLog replays the log at dir into a fresh entry map and reopens it for
+// appending. A torn last line, left by a crash mid-write, is dropped by
+// compacting the log.
+//
+// Other processes may share the log, so compaction and appends hold its lock,
+// and a writer whose file was replaced by another process's compaction
+// reopens it before appending.
+func openLog(dir string) (*diskLog, []record, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	path := filepath.Join(dir, logFileName)
+	unlock, err := state.Lock(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlock()
+
+	records, lines, torn, err := readLog(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	live := replay(records)
+	if torn || lines > 2*len(live)+64 {
+		if err := writeLog(path, live); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open cache log: %w", err)
+	}
+
+	return &diskLog{path: path, file: file}, live, nil
+}
+
+func readLog(path string) ([]record, int, bool, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, 0, false, nil
+	}
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to open cache log: %w", err)
+	}
+	defer file.Close()
+
+	var records []record
+	lines := 0
+	torn := false
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lines++
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			torn = true
+			continue
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, false, fmt.Errorf("failed to read cache log: %w", err)
+	}
+
+	return records, lines, torn, nil
+}
+
+func replay(records []record) []record {
+	index := make(map[[2]string]int)
+	var live []record
+	for _, rec := range records {
+		id := [2]string{rec.Service, rec.Key}
+		i, exists := index[id]
+		switch {
+		case rec.Deleted && exists:
+			live[i].Deleted = true
+		case rec.Deleted:
+		case exists:
+			live[i] = rec
+		default:
+			index[id] = len(live)
+			live = append(live, rec)
+		}
+	}
+
+	compacted := live[:0]
+	for _, rec := range live {
+		if !rec.Deleted {
+			compacted = append(compacted, rec)
+		}
+	}
+	return compacted
+}
+
+// writeLog atomically replaces the log at path with records.
+func writeLog(path string, records []record) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), logFileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache log: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for _, rec := range records {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to encode cache entry: %w", err)
+		}
+		w.Write(line)
+		w.WriteByte('\n')
+	}
+
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache log: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync cache log: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close cache log: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace cache log: %w", err)
+	}
+	return nil
+}
+
+func (l *diskLog) append(rec record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if rec.Time == 0 {
+		rec.Time = time.Now().Unix()
+	}
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	unlock, err := state.Lock(l.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := l.reopenIfReplaced(); err != nil {
+		return err
+	}
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to append to cache log: %w", err)
+	}
+	return l.file.Sync()
+}
+
+func (l *diskLog) rewrite(records []record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	unlock, err := state.Lock(l.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := writeLog(l.path, records); err != nil {
+		return err
+	}
+	return l.reopen()
+}
+
+// reopenIfReplaced reopens the log when another process has compacted it, so
+// that appends do not go to the unlinked old file.
+func (l *diskLog) reopenIfReplaced() error {
+	current, err := l.file.Stat()
+	if err != nil {
+		return l.reopen()
+	}
+	onDisk, err := os.Stat(l.path)
+	if err == nil && os.SameFile(current, onDisk) {
+		return nil
+	}
+	return l.reopen()
+}
+
+func (l *diskLog) reopen() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open cache log: %w", err)
+	}
+	l.file.Close()
+	l.file = file
+	return nil
+}
+
+func (l *diskLog) close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+)
+
+// KeyVersion is mixed into every hash; bump it when the key layout or text
+// normalization changes so old entries stop matching.
+const KeyVersion = "1"
+
+// Key identifies a cached translation. Everything that can change a
+// provider's answer belongs in it.
+type Key struct {
+	Provider      string
+	Text          string
+	Source        string
+	Target        string
+	Model         string
+	PromptVersion string
+	Options       map[string]string
+}
+
+// NormalizeText trims the text and collapses runs of whitespace so trivially
+// different inputs share a cache entry.
+func NormalizeText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func (k Key) Hash() string {
+	optionNames := make([]string, 0, len(k.Options))
+	for name := range k.Options {
+		optionNames = append(optionNames, name)
+	}
+	sort.Strings(optionNames)
+
+	h := sha256.New()
+	write := func(s string) {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+
+	write("v" + KeyVersion)
+	write(k.Provider)
+	write(k.Model)
+	write(k.PromptVersion)
+	write(strings.ToLower(k.Source))
+	write(strings.ToLower(k.Target))
+	for _, name := range optionNames {
+		write(name + "=" + k.Options[name])
+	}
+	write(NormalizeText(k.Text))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
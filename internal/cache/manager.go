@@ -1,61 +1,307 @@
 package cache
 
 import (
-	"fmt"
+	"container/list"
 	"sync"
+	"time"
 )
 
+type Options struct {
+	MaxEntries  int
+	MaxBytes    int64
+	TTL         time.Duration
+	ProviderTTL map[string]time.Duration
+}
+
+type Stats struct {
+	Entries     int
+	Bytes       int64
+	Hits        uint64
+	Misses      uint64
+	Evictions   uint64
+	Expirations uint64
+}
+
+// Entry is a read-only view of a cached translation.
+type Entry struct {
+	Provider    string
+	Key         string
+	Text        string
+	Source      string
+	Target      string
+	Translation string
+	Created     time.Time
+}
+
+type entry struct {
+	service     string
+	key         string
+	text        string
+	source      string
+	target      string
+	translation string
+	created     time.Time
+}
+
+func (e *entry) size() int64 {
+	return int64(len(e.service) + len(e.key) + len(e.text) + len(e.translation))
+}
+
 type Manager struct {
-	cache map[string]map[string]string
-	mu    sync.RWMutex
+	cache   map[string]map[string]*list.Element
+	lru     *list.List
+	options Options
+	stats   Stats
+	log     *diskLog
+	mu      sync.Mutex
+}
+
+func DefaultOptions() Options {
+	return Options{
+		MaxEntries: 10000,
+		MaxBytes:   16 << 20,
+		TTL:        7 * 24 * time.Hour,
+	}
 }
 
 func NewManager() *Manager {
+	return NewManagerWithOptions(Options{})
+}
+
+// NewManagerWithOptions returns an in-memory cache. Zero limits and TTLs mean
+// unbounded.
+func NewManagerWithOptions(options Options) *Manager {
 	return &Manager{
-		cache: make(map[string]map[string]string),
+		cache:   make(map[string]map[string]*list.Element),
+		lru:     list.New(),
+		options: options,
 	}
 }
 
-func (m *Manager) GetCacheKey(text, source, target string) string {
-	return fmt.Sprintf("%s|%s|%s", text, source, target)
+// NewPersistentManager loads the cache log in dir and keeps appending new
+// entries to it.
+func NewPersistentManager(dir string, options Options) (*Manager, error) {
+	log, records, err := openLog(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	m := NewManagerWithOptions(options)
+	for _, rec := range records {
+		m.insert(&entry{
+			service:     rec.Service,
+			key:         rec.Key,
+			text:        rec.Text,
+			source:      rec.Source,
+			target:      rec.Target,
+			translation: rec.Translation,
+			created:     time.Unix(rec.Time, 0),
+		})
+	}
+	m.log = log
+	m.expireAll(time.Now())
+	m.evict()
+
+	return m, nil
 }
 
-func (m *Manager) Get(service, text, source, target string) (string, bool) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+func (m *Manager) Get(key Key) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	if serviceCache, exists := m.cache[service]; exists {
-		cacheKey := m.GetCacheKey(text, source, target)
-		if translation, cached := serviceCache[cacheKey]; cached {
-			return translation, true
+	if serviceCache, exists := m.cache[key.Provider]; exists {
+		cacheKey := key.Hash()
+		if elem, cached := serviceCache[cacheKey]; cached {
+			e := elem.Value.(*entry)
+			if m.expired(e, time.Now()) {
+				m.discard(elem)
+				m.stats.Expirations++
+				m.stats.Misses++
+				return "", false
+			}
+			m.lru.MoveToFront(elem)
+			m.stats.Hits++
+			return e.translation, true
 		}
 	}
+	m.stats.Misses++
 	return "", false
 }
 
-func (m *Manager) Set(service, text, source, target, translation string) {
+func (m *Manager) Set(key Key, translation string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if _, exists := m.cache[service]; !exists {
-		m.cache[service] = make(map[string]string)
+	e := &entry{
+		service:     key.Provider,
+		key:         key.Hash(),
+		text:        NormalizeText(key.Text),
+		source:      key.Source,
+		target:      key.Target,
+		translation: translation,
+		created:     time.Now(),
 	}
-	cacheKey := m.GetCacheKey(text, source, target)
-	m.cache[service][cacheKey] = translation
+	m.insert(e)
+
+	if m.log != nil {
+		_ = m.log.append(record{
+			Service:     e.service,
+			Key:         e.key,
+			Text:        e.text,
+			Source:      e.source,
+			Target:      e.target,
+			Translation: e.translation,
+		})
+	}
+
+	m.evict()
+}
+
+// Entries returns the live entries, most recently used first.
+func (m *Manager) Entries() []Entry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	entries := make([]Entry, 0, m.lru.Len())
+	for elem := m.lru.Front(); elem != nil; elem = elem.Next() {
+		e := elem.Value.(*entry)
+		if m.expired(e, now) {
+			continue
+		}
+		entries = append(entries, Entry{
+			Provider:    e.service,
+			Key:         e.key,
+			Text:        e.text,
+			Source:      e.source,
+			Target:      e.target,
+			Translation: e.translation,
+			Created:     e.created,
+		})
+	}
+	return entries
+}
+
+// Delete removes a single entry by provider and hashed key.
+func (m *Manager) Delete(provider, key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, exists := m.cache[provider][key]
+	if !exists {
+		return false
+	}
+	m.discard(elem)
+	return true
 }
 
 func (m *Manager) Clear() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.cache = make(map[string]map[string]string)
+	m.cache = make(map[string]map[string]*list.Element)
+	m.lru.Init()
+	m.stats.Entries = 0
+	m.stats.Bytes = 0
+
+	if m.log != nil {
+		_ = m.log.rewrite(nil)
+	}
+}
+
+func (m *Manager) Close() error {
+	if m.log == nil {
+		return nil
+	}
+	return m.log.close()
 }
 
 func (m *Manager) Size() int {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	total := 0
-	for _, serviceCache := range m.cache {
-		total += len(serviceCache)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats.Entries
+}
+
+func (m *Manager) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+func (m *Manager) insert(e *entry) {
+	serviceCache, exists := m.cache[e.service]
+	if !exists {
+		serviceCache = make(map[string]*list.Element)
+		m.cache[e.service] = serviceCache
+	}
+
+	if elem, exists := serviceCache[e.key]; exists {
+		m.remove(elem)
+	}
+
+	serviceCache[e.key] = m.lru.PushFront(e)
+	m.stats.Entries++
+	m.stats.Bytes += e.size()
+}
+
+func (m *Manager) remove(elem *list.Element) {
+	e := m.lru.Remove(elem).(*entry)
+	delete(m.cache[e.service], e.key)
+	if len(m.cache[e.service]) == 0 {
+		delete(m.cache, e.service)
+	}
+	m.stats.Entries--
+	m.stats.Bytes -= e.size()
+}
+
+// discard removes an entry and logs the removal so it is not resurrected on
+// the next load.
+func (m *Manager) discard(elem *list.Element) {
+	e := elem.Value.(*entry)
+	m.remove(elem)
+
+	if m.log != nil {
+		_ = m.log.append(record{Service: e.service, Key: e.key, Deleted: true})
+	}
+}
+
+// evict drops least recently used entries until the cache fits its limits.
+func (m *Manager) evict() {
+	for m.overLimit() {
+		elem := m.lru.Back()
+		if elem == nil {
+			return
+		}
+		m.discard(elem)
+		m.stats.Evictions++
+	}
+}
+
+func (m *Manager) overLimit() bool {
+	if m.options.MaxEntries > 0 && m.stats.Entries > m.options.MaxEntries {
+		return true
 	}
-	return total
+	return m.options.MaxBytes > 0 && m.stats.Bytes > m.options.MaxBytes
+}
+
+func (m *Manager) expireAll(now time.Time) {
+	for elem := m.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		if m.expired(elem.Value.(*entry), now) {
+			m.discard(elem)
+			m.stats.Expirations++
+		}
+		elem = prev
+	}
+}
+
+func (m *Manager) ttl(service string) time.Duration {
+	if ttl, exists := m.options.ProviderTTL[service]; exists {
+		return ttl
+	}
+	return m.options.TTL
+}
+
+func (m *Manager) expired(e *entry, now time.Time) bool {
+	ttl := m.ttl(e.service)
+	return ttl > 0 && now.Sub(e.created) > ttl
 }
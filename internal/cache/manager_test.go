@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testKey(provider, text string) Key {
+	return Key{Provider: provider, Text: text, Source: "en", Target: "de"}
+}
+
+func TestEvictionOrder(t *testing.T) {
+	// Every entry below has the same size: one-letter text and translation.
+	one := (&entry{service: "GOOGLE", key: testKey("GOOGLE", "a").Hash(), text: "a", translation: "A"}).size()
+
+	tests := []struct {
+		name          string
+		options       Options
+		ops           []string // "set <text>" or "get <text>"
+		want          []string // texts left, most recently used first
+		wantEvictions uint64
+	}{
+		{
+			name:          "oldest goes first",
+			options:       Options{MaxEntries: 2},
+			ops:           []string{"set a", "set b", "set c"},
+			want:          []string{"c", "b"},
+			wantEvictions: 1,
+		},
+		{
+			name:          "get refreshes an entry",
+			options:       Options{MaxEntries: 2},
+			ops:           []string{"set a", "set b", "get a", "set c"},
+			want:          []string{"c", "a"},
+			wantEvictions: 1,
+		},
+		{
+			name:    "set replaces without evicting",
+			options: Options{MaxEntries: 2},
+			ops:     []string{"set a", "set b", "set a"},
+			want:    []string{"a", "b"},
+		},
+		{
+			name:          "byte limit evicts until it fits",
+			options:       Options{MaxBytes: 2 * one},
+			ops:           []string{"set a", "set b", "get a", "set c", "set d"},
+			want:          []string{"d", "c"},
+			wantEvictions: 2,
+		},
+		{
+			name: "unbounded",
+			ops:  []string{"set a", "set b", "set c"},
+			want: []string{"c", "b", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManagerWithOptions(tt.options)
+			for _, op := range tt.ops {
+				action, text, _ := strings.Cut(op, " ")
+				if action == "set" {
+					m.Set(testKey("GOOGLE", text), strings.ToUpper(text))
+				} else {
+					m.Get(testKey("GOOGLE", text))
+				}
+			}
+
+			var got []string
+			for _, e := range m.Entries() {
+				got = append(got, e.Text)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("entries = %v, want %v", got, tt.want)
+			}
+			if evictions := m.Stats().Evictions; evictions != tt.wantEvictions {
+				t.Errorf("evictions = %d, want %d", evictions, tt.wantEvictions)
+			}
+		})
+	}
+}
+
+func TestTTL(t *testing.T) {
+	options := Options{
+		TTL:         time.Hour,
+		ProviderTTL: map[string]time.Duration{"DEEPL": 24 * time.Hour, "OPENAI": 0},
+	}
+
+	tests := []struct {
+		provider string
+		age      time.Duration
+		want     bool
+	}{
+		{"GOOGLE", 30 * time.Minute, true},
+		{"GOOGLE", 2 * time.Hour, false},
+		{"DEEPL", 2 * time.Hour, true},
+		{"DEEPL", 25 * time.Hour, false},
+		{"OPENAI", 365 * 24 * time.Hour, true},
+	}
+
+	for _, tt := range tests {
+		m := NewManagerWithOptions(options)
+		key := testKey(tt.provider, "hello")
+		m.Set(key, "hallo")
+		backdate(m, key, tt.age)
+
+		_, got := m.Get(key)
+		if got != tt.want {
+			t.Errorf("%s entry %v old: cached = %v, want %v", tt.provider, tt.age, got, tt.want)
+		}
+
+		wantExpirations := uint64(0)
+		if !tt.want {
+			wantExpirations = 1
+		}
+		if stats := m.Stats(); stats.Expirations != wantExpirations || stats.Entries != int(1-wantExpirations) {
+			t.Errorf("%s entry %v old: stats = %+v", tt.provider, tt.age, stats)
+		}
+	}
+}
+
+func TestExpiredEntriesAreNotListed(t *testing.T) {
+	m := NewManagerWithOptions(Options{TTL: time.Hour})
+	m.Set(testKey("GOOGLE", "old"), "alt")
+	m.Set(testKey("GOOGLE", "new"), "neu")
+	backdate(m, testKey("GOOGLE", "old"), 2*time.Hour)
+
+	entries := m.Entries()
+	if len(entries) != 1 || entries[0].Text != "new" {
+		t.Errorf("entries = %+v, want only the new one", entries)
+	}
+}
+
+func backdate(m *Manager, key Key, age time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cache[key.Provider][key.Hash()].Value.(*entry).created = time.Now().Add(-age)
+}
@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"translatego/internal/core"
+	"translatego/internal/languages"
+	"translatego/pkg/translate"
+)
+
+const (
+	warmRateLimitDelay    = 6 * time.Second
+	warmRateLimitAttempts = 10
+)
+
+func init() {
+	commands["cache"] = runCache
+}
+
+func runCache(ctx context.Context, c *core.Core, args []string, stdout, stderr io.Writer) error {
+	usage := func() {
+		fmt.Fprintln(stderr, "Usage: translatego cache warm phrases.txt -to de,fr [-p DEEPL,GOOGLE]")
+	}
+
+	if len(args) == 0 {
+		usage()
+		return flag.ErrHelp
+	}
+
+	switch args[0] {
+	case "warm":
+		return runCacheWarm(ctx, c, args[1:], stdout, stderr)
+	default:
+		usage()
+		return fmt.Errorf("unknown cache command %q", args[0])
+	}
+}
+
+// runCacheWarm translates every phrase in a file into every target language
+// and stores the results in the persistent cache. Phrases already cached are
+// skipped, so an interrupted run resumes where it stopped.
+func runCacheWarm(ctx context.Context, c *core.Core, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("cache warm", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	from := fs.String("from", translate.AutoDetect, "source language, or auto to detect it")
+	to := fs.String("to", "", "comma-separated target languages")
+	providers := fs.String("p", "", "comma-separated providers to use (default: all enabled)")
+	maxWait := fs.Duration("max-wait", 5*time.Minute, "longest time to wait for a rate limited provider")
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("cache warm needs exactly one phrase file")
+	}
+	if c.Cache == nil {
+		return errors.New("cache is disabled, enable settings.cache_enabled first")
+	}
+	if c.CacheErr != nil {
+		return fmt.Errorf("cache warm needs the on-disk cache: %w", c.CacheErr)
+	}
+
+	targets := splitLangs(*to)
+	if len(targets) == 0 {
+		return errors.New("cache warm needs at least one target language (-to)")
+	}
+
+	selected, err := selectProviders(c, *providers)
+	if err != nil {
+		return err
+	}
+
+	phrases, err := readPhrases(positional[0])
+	if err != nil {
+		return err
+	}
+
+	total := len(phrases) * len(targets)
+	var translated, cached, failed int
+	step := 0
+	for _, phrase := range phrases {
+		for _, target := range targets {
+			step++
+			if err := ctx.Err(); err != nil {
+				fmt.Fprintf(stdout, "\nInterrupted at %d/%d; run the same command again to resume.\n", step-1, total)
+				return err
+			}
+
+			resp, err := warmOne(ctx, c.Client, translate.Request{
+				Text:      phrase,
+				Source:    *from,
+				Target:    target,
+				Providers: selected,
+				Strategy:  translate.StrategyAll,
+				Priority:  translate.PriorityBatch,
+				MaxWait:   requestMaxWait(*maxWait),
+				OnQueued:  printQueued(stderr),
+			})
+			if err != nil && !errors.Is(err, translate.ErrAllProvidersFailed) {
+				return err
+			}
+
+			var ok, hit, bad int
+			for _, result := range resp.Results {
+				switch {
+				case result.Err != nil:
+					bad++
+				case result.Cached:
+					hit++
+				default:
+					ok++
+				}
+			}
+			translated += ok
+			cached += hit
+			failed += bad
+
+			fmt.Fprintf(stdout, "[%d/%d] %s: %s — %d translated, %d cached, %d failed\n",
+				step, total, target, truncateLine(phrase, 40), ok, hit, bad)
+			printWarnings(stderr, resp.Results)
+			printFailures(stderr, resp.Results)
+		}
+	}
+
+	fmt.Fprintf(stdout, "Done: %d translated, %d already cached, %d failed\n", translated, cached, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d translations failed, run the same command again to retry them", failed)
+	}
+	return nil
+}
+
+// warmOne retries providers that were turned away by a rate limit, waiting as
+// long as the limiter or the provider asked.
+func warmOne(ctx context.Context, client *translate.Client, req translate.Request) (translate.Response, error) {
+	resp, err := client.Translate(ctx, req)
+
+	for attempt := 0; attempt < warmRateLimitAttempts; attempt++ {
+		var limited []string
+		var delay time.Duration
+		for _, result := range resp.Results {
+			if serviceErr, ok := translate.AsServiceError(result.Err); ok && serviceErr.ErrorType == translate.ErrorTypeRateLimit {
+				limited = append(limited, result.Provider)
+				delay = max(delay, serviceErr.RetryAfter)
+			}
+		}
+		if len(limited) == 0 {
+			break
+		}
+		if delay == 0 {
+			delay = warmRateLimitDelay
+		}
+
+		select {
+		case <-ctx.Done():
+			return resp, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		retryReq := req
+		retryReq.Providers = limited
+		retried, _ := client.Translate(ctx, retryReq)
+		for _, result := range retried.Results {
+			for i := range resp.Results {
+				if resp.Results[i].Provider == result.Provider {
+					resp.Results[i] = result
+				}
+			}
+		}
+	}
+
+	for _, result := range resp.Results {
+		if result.Err == nil {
+			return resp, nil
+		}
+	}
+	return resp, err
+}
+
+func readPhrases(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var phrases []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		phrases = append(phrases, line)
+	}
+	return phrases, scanner.Err()
+}
+
+func splitLangs(s string) []string {
+	var langs []string
+	for _, lang := range strings.Split(s, ",") {
+		if lang = strings.TrimSpace(lang); lang != "" {
+			langs = append(langs, languages.Canonical(lang))
+		}
+	}
+	return langs
+}
+
+func truncateLine(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+	return string(runes[:width-1]) + "…"
+}
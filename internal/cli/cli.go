@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"translatego/internal/core"
+	"translatego/pkg/translate"
+)
+
+type command func(ctx context.Context, c *core.Core, args []string, stdout, stderr io.Writer) error
+
+var commands = map[string]command{}
+
+// Run executes a non-interactive command and returns the process exit code.
+func Run(args []string, stdout, stderr io.Writer) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	c := core.Load()
+	defer c.Close()
+	if c.CacheErr != nil {
+		fmt.Fprintf(stderr, "warning: %v\n", c.CacheErr)
+	}
+
+	run := runTranslate
+	if len(args) > 0 {
+		if cmd, ok := commands[args[0]]; ok {
+			run = cmd
+			args = args[1:]
+		}
+	}
+
+	if err := run(ctx, c, args, stdout, stderr); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(stderr, "translatego: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func runTranslate(ctx context.Context, c *core.Core, args []string, stdout, stderr io.Writer) error {
+	settings := c.Config.GetSettings()
+
+	fs := flag.NewFlagSet("translatego", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	from := fs.String("from", translate.AutoDetect, "source language, or auto to detect it")
+	to := fs.String("to", defaultString(settings.DefaultTargetLang, "ru"), "target language")
+	providers := fs.String("p", "", "comma-separated providers to use (default: all enabled)")
+	strategy := fs.String("strategy", string(c.Client.Strategy()), "fan-out strategy: all, first, chain or quorum")
+	quorum := fs.Int("quorum", settings.Quorum, "number of agreeing providers for the quorum strategy")
+	maxWait := fs.Duration("max-wait", translate.DefaultMaxWait, "longest time to wait for a rate limited provider")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: translatego [flags] text...")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	text := strings.Join(fs.Args(), " ")
+	if text == "" {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	parsed, err := translate.ParseStrategy(*strategy)
+	if err != nil {
+		return err
+	}
+
+	selected, err := selectProviders(c, *providers)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Client.Translate(ctx, translate.Request{
+		Text:      text,
+		Source:    *from,
+		Target:    *to,
+		Providers: selected,
+		Strategy:  parsed,
+		Quorum:    *quorum,
+		MaxWait:   requestMaxWait(*maxWait),
+		OnQueued:  printQueued(stderr),
+	})
+	printWarnings(stderr, resp.Results)
+
+	if parsed.SingleResult() {
+		if err != nil {
+			printFailures(stderr, resp.Results)
+			return summarize(err)
+		}
+		fmt.Fprintln(stdout, resp.Text)
+		return nil
+	}
+
+	for _, result := range resp.Results {
+		if result.Err == nil {
+			fmt.Fprintf(stdout, "%s: %s\n", result.Provider, result.Text)
+		}
+	}
+	printFailures(stderr, resp.Results)
+	return summarize(err)
+}
+
+// selectProviders returns the providers named with -p, or every usable one.
+// An empty list would let the client ask all providers, including those
+// without a key or quota, so it is an error instead.
+func selectProviders(c *core.Core, names string) ([]string, error) {
+	if selected := splitList(names); len(selected) > 0 {
+		return selected, nil
+	}
+	selected := c.UsableProviders()
+	if len(selected) == 0 {
+		return nil, errors.New("no usable providers: every enabled provider lacks an API key or has used up its quota")
+	}
+	return selected, nil
+}
+
+// parseInterspersed parses fs allowing flags after positional arguments, as in
+// "cache warm phrases.txt -to de".
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// requestMaxWait maps "-max-wait 0" to a request that never waits; a zero
+// Request.MaxWait would mean the client default instead.
+func requestMaxWait(maxWait time.Duration) time.Duration {
+	if maxWait <= 0 {
+		return -1
+	}
+	return maxWait
+}
+
+func printQueued(w io.Writer) func(provider string, start time.Time) {
+	var mu sync.Mutex
+	return func(provider string, start time.Time) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(w, "%s: rate limited, waiting %s\n", provider, time.Until(start).Round(time.Second))
+	}
+}
+
+func printWarnings(w io.Writer, results []translate.Result) {
+	for _, result := range results {
+		if result.Warning != "" {
+			fmt.Fprintf(w, "warning: %s\n", result.Warning)
+		}
+	}
+}
+
+func printFailures(w io.Writer, results []translate.Result) {
+	for _, result := range results {
+		if result.Err == nil || errors.Is(result.Err, translate.ErrSkipped) {
+			continue
+		}
+		if _, ok := translate.AsServiceError(result.Err); ok {
+			fmt.Fprintln(w, result.Err)
+		} else {
+			fmt.Fprintf(w, "%s: %v\n", result.Provider, result.Err)
+		}
+	}
+}
+
+// summarize drops the per-provider details from err, which printFailures has
+// already reported.
+func summarize(err error) error {
+	if errors.Is(err, translate.ErrAllProvidersFailed) {
+		return translate.ErrAllProvidersFailed
+	}
+	return err
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, strings.ToUpper(item))
+		}
+	}
+	return items
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
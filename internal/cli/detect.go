@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"translatego/internal/config"
+	"translatego/internal/core"
+	"translatego/pkg/translate"
+)
+
+func init() {
+	commands["detect"] = runDetect
+}
+
+// runDetect prints the likely languages of the text given as arguments, or
+// of standard input when there are none.
+func runDetect(ctx context.Context, c *core.Core, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("detect", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	limit := fs.Int("n", 3, "number of candidates to show")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: translatego detect [-n 3] [text...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	text := strings.Join(fs.Args(), " ")
+	if text == "" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		text = string(data)
+	}
+
+	candidates := translate.DetectLanguage(text)
+	if len(candidates) == 0 {
+		return errors.New("no letters to detect a language from")
+	}
+	if *limit > 0 && len(candidates) > *limit {
+		candidates = candidates[:*limit]
+	}
+
+	names := config.GetLanguageNames()
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	for i, candidate := range candidates {
+		if i > 0 && candidate.Confidence < 0.005 {
+			break
+		}
+		fmt.Fprintf(w, "%s\t%3.0f%%\t%s\n", candidate.Language, candidate.Confidence*100, names[candidate.Language])
+	}
+	return w.Flush()
+}
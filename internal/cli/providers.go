@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"translatego/internal/breaker"
+	"translatego/internal/core"
+)
+
+func init() {
+	commands["providers"] = runProviders
+}
+
+func runProviders(ctx context.Context, c *core.Core, args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 || args[0] != "list" {
+		fmt.Fprintln(stderr, "Usage: translatego providers list")
+		if len(args) == 0 {
+			return flag.ErrHelp
+		}
+		return fmt.Errorf("unknown providers command %q", args[0])
+	}
+
+	providers := c.Config.GetProviders()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tENABLED\tAPI KEY\tQUOTA\tCIRCUIT\tP90")
+	for _, name := range names {
+		provider := providers[name]
+
+		enabled := "no"
+		if provider.Enabled {
+			enabled = "yes"
+		}
+
+		key := "-"
+		if c.Config.IsAPIKeyRequired(name) {
+			key = "missing"
+			if c.Config.GetAPIKey(name) != "" {
+				key = "set"
+			}
+		}
+
+		quota := "unlimited"
+		status := c.Quota.Status(name)
+		switch {
+		case status.Exhausted():
+			quota = fmt.Sprintf("used up (%s)", status.Summary())
+		case status.Summary() != "":
+			quota = status.Remaining()
+		}
+
+		circuit, retryIn := c.Breaker.State(name)
+		circuitText := string(circuit)
+		if circuit == breaker.Open {
+			circuitText = fmt.Sprintf("open (retry in %ds)", int(math.Ceil(retryIn.Seconds())))
+		}
+
+		p90 := "-"
+		if latency, ok := c.Latency.Percentile(name, 0.9); ok {
+			p90 = latency.Round(time.Millisecond).String()
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", name, enabled, key, quota, circuitText, p90)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"translatego/internal/core"
+	"translatego/internal/languages"
+	"translatego/internal/memory"
+)
+
+const toolVersion = "1.0.0"
+
+func init() {
+	commands["tm"] = runTM
+}
+
+func runTM(ctx context.Context, c *core.Core, args []string, stdout, stderr io.Writer) error {
+	usage := func() {
+		fmt.Fprintln(stderr, "Usage: translatego tm import file.tmx")
+		fmt.Fprintln(stderr, "       translatego tm export [--pair en-de|zh-Hans:pt-BR] [-o file.tmx]")
+	}
+
+	if len(args) == 0 {
+		usage()
+		return flag.ErrHelp
+	}
+
+	switch args[0] {
+	case "import":
+		return runTMImport(c, args[1:], stdout, stderr)
+	case "export":
+		return runTMExport(c, args[1:], stdout, stderr)
+	default:
+		usage()
+		return fmt.Errorf("unknown tm command %q", args[0])
+	}
+}
+
+func runTMImport(c *core.Core, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("tm import", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("tm import needs exactly one TMX file")
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	segments, err := memory.ReadTMX(file)
+	if err != nil {
+		return err
+	}
+
+	added, cached := 0, 0
+	for _, seg := range segments {
+		isNew, err := c.Memory.Add(seg)
+		if err != nil {
+			return err
+		}
+		if isNew {
+			added++
+		}
+		if seg.Provider != "" && c.Client.Store(seg.Provider, seg.Source, seg.SourceLang, seg.TargetLang, seg.Target) == nil && c.Cache != nil {
+			cached++
+		}
+	}
+
+	fmt.Fprintf(stdout, "Imported %d of %d segments (%d cached)\n", added, len(segments), cached)
+	return nil
+}
+
+func runTMExport(c *core.Core, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("tm export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	pair := fs.String("pair", "", "language pair to export, e.g. en-de, or zh-Hans:pt-BR for tags with a region or script (default: all)")
+	output := fs.String("o", "", "write to file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var sourceLang, targetLang string
+	if *pair != "" {
+		var err error
+		if sourceLang, targetLang, err = parsePair(*pair); err != nil {
+			return err
+		}
+	}
+
+	var segments []memory.Segment
+	for _, seg := range c.Memory.Segments() {
+		if sourceLang != "" && (seg.SourceLang != sourceLang || seg.TargetLang != targetLang) {
+			continue
+		}
+		segments = append(segments, seg)
+	}
+
+	w := stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	written, err := memory.WriteTMX(w, segments, toolVersion)
+	if err != nil {
+		return err
+	}
+
+	if skipped := len(segments) - written; skipped > 0 {
+		fmt.Fprintf(stderr, "Skipped %d segments whose source language is unknown\n", skipped)
+	}
+	if *output != "" {
+		fmt.Fprintf(stdout, "Exported %d segments to %s\n", written, *output)
+	}
+	return nil
+}
+
+// parsePair splits a language pair such as "en-de". Tags with a region or
+// script contain hyphens themselves, so such pairs are written with a colon:
+// "zh-Hans:pt-BR".
+func parsePair(pair string) (string, string, error) {
+	source, target, found := strings.Cut(pair, ":")
+	if !found {
+		source, target, found = strings.Cut(pair, "-")
+		if !found || !isSubtag(source) || !isSubtag(target) {
+			return "", "", fmt.Errorf("invalid language pair %q, expected e.g. en-de or zh-Hans:pt-BR", pair)
+		}
+	}
+
+	source, target = languages.Canonical(source), languages.Canonical(target)
+	if source == "" || target == "" {
+		return "", "", fmt.Errorf("invalid language pair %q, expected e.g. en-de or zh-Hans:pt-BR", pair)
+	}
+	return source, target, nil
+}
+
+// isSubtag reports whether s is a bare language subtag such as "en" or "haw".
+func isSubtag(s string) bool {
+	if len(s) < 2 || len(s) > 3 {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
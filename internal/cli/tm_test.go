@@ -0,0 +1,35 @@
+package cli
+
+import "testing"
+
+func TestParsePair(t *testing.T) {
+	tests := []struct {
+		pair           string
+		source, target string
+		wantErr        bool
+	}{
+		{pair: "en-de", source: "en", target: "de"},
+		{pair: "EN-De", source: "en", target: "de"},
+		{pair: "en:de", source: "en", target: "de"},
+		{pair: "zh-Hans:pt-BR", source: "zh-Hans", target: "pt-BR"},
+		{pair: "pt_br:en", source: "pt-BR", target: "en"},
+		{pair: "zh-Hans-pt-BR", wantErr: true},
+		{pair: "en-GB-de", wantErr: true},
+		{pair: "en", wantErr: true},
+		{pair: "en:", wantErr: true},
+		{pair: ":de", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		source, target, err := parsePair(tt.pair)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parsePair(%q) = %s, %s, want an error", tt.pair, source, target)
+			}
+			continue
+		}
+		if err != nil || source != tt.source || target != tt.target {
+			t.Errorf("parsePair(%q) = %s, %s, %v, want %s, %s", tt.pair, source, target, err, tt.source, tt.target)
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"time"
 	"translatego/internal/utils"
 )
 
@@ -16,20 +18,84 @@ type Config struct {
 }
 
 type ProviderConfig struct {
-	Name    string            `json:"name"`
-	URL     string            `json:"url"`
-	Method  string            `json:"method"`
-	Headers map[string]string `json:"headers"`
-	Body    string            `json:"body,omitempty"`
-	Enabled bool              `json:"enabled"`
-	APIKey  string            `json:"api_key,omitempty"`
+	Name        string            `json:"name"`
+	URL         string            `json:"url"`
+	Method      string            `json:"method"`
+	Headers     map[string]string `json:"headers"`
+	Body        string            `json:"body,omitempty"`
+	Enabled     bool              `json:"enabled"`
+	APIKey      string            `json:"api_key,omitempty"`
+	CacheTTL    string            `json:"cache_ttl,omitempty"`
+	RateLimit   *RateLimitConfig  `json:"rate_limit,omitempty"`
+	Quota       *QuotaConfig      `json:"quota,omitempty"`
+	Retry       *RetryConfig      `json:"retry,omitempty"`
+	PostProcess *[]string         `json:"post_process,omitempty"`
+}
+
+// RetryConfig adjusts the retry policy; unset fields keep the inherited
+// value. Base and Max are durations such as "1s".
+type RetryConfig struct {
+	MaxAttempts *int                   `json:"max_attempts,omitempty"`
+	Base        string                 `json:"base,omitempty"`
+	Max         string                 `json:"max,omitempty"`
+	Multiplier  float64                `json:"multiplier,omitempty"`
+	Jitter      *float64               `json:"jitter,omitempty"`
+	ErrorTypes  map[string]RetryConfig `json:"error_types,omitempty"`
+}
+
+// RateLimitConfig overrides the built-in limits of a provider; zero fields
+// keep the default.
+type RateLimitConfig struct {
+	Requests   int    `json:"requests,omitempty"`
+	Characters int    `json:"characters,omitempty"`
+	Interval   string `json:"interval,omitempty"`
+	Burst      int    `json:"burst,omitempty"`
+}
+
+// QuotaConfig overrides the built-in quotas of a provider. WarnAt is the used
+// fraction at which a warning is shown.
+type QuotaConfig struct {
+	DailyCharacters   int64   `json:"daily_characters,omitempty"`
+	MonthlyCharacters int64   `json:"monthly_characters,omitempty"`
+	DailyTokens       int64   `json:"daily_tokens,omitempty"`
+	MonthlyTokens     int64   `json:"monthly_tokens,omitempty"`
+	WarnAt            float64 `json:"warn_at,omitempty"`
 }
 
 type Settings struct {
-	DefaultTargetLang string `json:"default_target_lang"`
-	MaxRetries        int    `json:"max_retries"`
-	TimeoutSeconds    int    `json:"timeout_seconds"`
-	CacheEnabled      bool   `json:"cache_enabled"`
+	DefaultTargetLang string         `json:"default_target_lang"`
+	MaxRetries        int            `json:"max_retries"`
+	TimeoutSeconds    int            `json:"timeout_seconds"`
+	CacheEnabled      bool           `json:"cache_enabled"`
+	Strategy          string         `json:"strategy,omitempty"`
+	Quorum            int            `json:"quorum,omitempty"`
+	ProviderOrder     []string       `json:"provider_order,omitempty"`
+	Cache             CacheSettings  `json:"cache"`
+	TMThreshold       float64        `json:"tm_threshold,omitempty"`
+	Concurrency       Concurrency    `json:"concurrency"`
+	Retry             *RetryConfig   `json:"retry,omitempty"`
+	CircuitBreaker    *BreakerConfig `json:"circuit_breaker,omitempty"`
+	Hedging           *bool          `json:"hedging,omitempty"`
+}
+
+// BreakerConfig overrides when a provider's circuit opens. Cooldown is a
+// duration such as "1m".
+type BreakerConfig struct {
+	FailureThreshold int      `json:"failure_threshold,omitempty"`
+	Cooldown         string   `json:"cooldown,omitempty"`
+	ErrorTypes       []string `json:"error_types,omitempty"`
+}
+
+// Concurrency bounds the provider requests running at once.
+type Concurrency struct {
+	Global      int `json:"global,omitempty"`
+	PerProvider int `json:"per_provider,omitempty"`
+}
+
+type CacheSettings struct {
+	MaxEntries int    `json:"max_entries,omitempty"`
+	MaxBytes   int64  `json:"max_bytes,omitempty"`
+	TTL        string `json:"ttl,omitempty"`
 }
 
 type Manager struct {
@@ -98,6 +164,8 @@ func (m *Manager) createDefaultConfig() error {
 			provider.Body = string(service.Body)
 		}
 
+		provider.CacheTTL = defaultCacheTTL(service.Name)
+
 		providers[service.Name] = provider
 	}
 
@@ -109,12 +177,37 @@ func (m *Manager) createDefaultConfig() error {
 			MaxRetries:        3,
 			TimeoutSeconds:    5,
 			CacheEnabled:      true,
+			Strategy:          "all",
+			Quorum:            2,
+			Cache: CacheSettings{
+				MaxEntries: 10000,
+				MaxBytes:   16 << 20,
+				TTL:        "168h",
+			},
+			TMThreshold: 0.75,
+			Concurrency: Concurrency{
+				Global:      8,
+				PerProvider: 2,
+			},
 		},
 	}
 
 	return m.Save()
 }
 
+// defaultCacheTTL keeps LLM answers longer than results from free endpoints,
+// which change or break more often.
+func defaultCacheTTL(providerName string) string {
+	switch providerName {
+	case "OPENAI", "OPENROUTER":
+		return "720h"
+	case "MYMEMORY", "LINGVA":
+		return "24h"
+	default:
+		return ""
+	}
+}
+
 func (m *Manager) Load() error {
 	data, err := os.ReadFile(m.configFile)
 	if err != nil {
@@ -150,6 +243,103 @@ func (m *Manager) GetConfig() *Config {
 	return m.config
 }
 
+func (m *Manager) GetSettings() Settings {
+	if m.config == nil {
+		return Settings{}
+	}
+	return m.config.Settings
+}
+
+// GetCacheTTLs returns the per-provider cache TTLs that parse as durations.
+func (m *Manager) GetCacheTTLs() map[string]time.Duration {
+	ttls := make(map[string]time.Duration)
+	if m.config == nil {
+		return ttls
+	}
+
+	for name, provider := range m.config.Providers {
+		if provider.CacheTTL == "" {
+			continue
+		}
+		if ttl, err := time.ParseDuration(provider.CacheTTL); err == nil {
+			ttls[name] = ttl
+		}
+	}
+	return ttls
+}
+
+// GetRateLimits returns the rate limit overrides of the providers that have
+// any.
+func (m *Manager) GetRateLimits() map[string]RateLimitConfig {
+	limits := make(map[string]RateLimitConfig)
+	if m.config == nil {
+		return limits
+	}
+
+	for name, provider := range m.config.Providers {
+		if provider.RateLimit != nil {
+			limits[name] = *provider.RateLimit
+		}
+	}
+	return limits
+}
+
+// GetRetryConfigs returns the retry overrides of the providers that have
+// any.
+func (m *Manager) GetRetryConfigs() map[string]RetryConfig {
+	configs := make(map[string]RetryConfig)
+	if m.config == nil {
+		return configs
+	}
+
+	for name, provider := range m.config.Providers {
+		if provider.Retry != nil {
+			configs[name] = *provider.Retry
+		}
+	}
+	return configs
+}
+
+// GetQuotas returns the quota overrides of the providers that have any.
+func (m *Manager) GetQuotas() map[string]QuotaConfig {
+	quotas := make(map[string]QuotaConfig)
+	if m.config == nil {
+		return quotas
+	}
+
+	for name, provider := range m.config.Providers {
+		if provider.Quota != nil {
+			quotas[name] = *provider.Quota
+		}
+	}
+	return quotas
+}
+
+// GetPostProcessing returns the cleanup steps of the providers that replace
+// the built-in ones; an empty list disables cleanup.
+func (m *Manager) GetPostProcessing() map[string][]string {
+	steps := make(map[string][]string)
+	if m.config == nil {
+		return steps
+	}
+
+	for name, provider := range m.config.Providers {
+		if provider.PostProcess != nil {
+			steps[name] = *provider.PostProcess
+		}
+	}
+	return steps
+}
+
+func (m *Manager) SetStrategy(strategy string) error {
+	if m.config == nil {
+		return fmt.Errorf("config is not initialized")
+	}
+
+	m.config.Settings.Strategy = strategy
+	return m.Save()
+}
+
 func (m *Manager) GetProviders() map[string]ProviderConfig {
 	if m.config == nil {
 		return nil
@@ -188,9 +378,39 @@ func (m *Manager) GetEnabledProviders() []utils.ServiceConfig {
 		}
 	}
 
+	order := m.config.Settings.ProviderOrder
+	if len(order) == 0 {
+		for _, service := range GetAvailableServices() {
+			order = append(order, service.Name)
+		}
+	}
+	sortServices(services, order)
+
 	return services
 }
 
+func sortServices(services []utils.ServiceConfig, order []string) {
+	rank := make(map[string]int, len(order))
+	for i, name := range order {
+		rank[name] = i
+	}
+
+	sort.SliceStable(services, func(i, j int) bool {
+		ri, ok := rank[services[i].Name]
+		if !ok {
+			ri = len(order)
+		}
+		rj, ok := rank[services[j].Name]
+		if !ok {
+			rj = len(order)
+		}
+		if ri != rj {
+			return ri < rj
+		}
+		return services[i].Name < services[j].Name
+	})
+}
+
 func (m *Manager) SetAPIKey(providerName, apiKey string) error {
 	if m.config == nil {
 		return fmt.Errorf("config is not initialized")
@@ -2,6 +2,7 @@ package config
 
 import (
 	"net/http"
+	"translatego/internal/languages"
 	"translatego/internal/utils"
 )
 
@@ -85,20 +86,14 @@ func GetAvailableServices() []ServiceConfig {
 }
 
 func GetSupportedLanguages() []string {
-	return []string{"ru", "en", "de", "fr", "es", "it", "ja", "zh", "ko", "ar"}
+	return languages.Tags()
 }
 
+// GetLanguageNames returns the native name of every supported language.
 func GetLanguageNames() map[string]string {
-	return map[string]string{
-		"ru": "Русский",
-		"en": "English",
-		"de": "Deutsch",
-		"fr": "Français",
-		"es": "Español",
-		"it": "Italiano",
-		"ja": "日本語",
-		"zh": "中文",
-		"ko": "한국어",
-		"ar": "العربية",
+	names := make(map[string]string)
+	for _, language := range languages.All() {
+		names[language.Tag] = language.Native
 	}
+	return names
 }
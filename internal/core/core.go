@@ -0,0 +1,325 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"translatego/internal/breaker"
+	"translatego/internal/cache"
+	"translatego/internal/config"
+	"translatego/internal/latency"
+	"translatego/internal/memory"
+	"translatego/internal/pool"
+	"translatego/internal/quota"
+	"translatego/internal/ratelimit"
+	"translatego/internal/retry"
+	"translatego/internal/state"
+	"translatego/internal/utils"
+	"translatego/pkg/translate"
+)
+
+type Core struct {
+	Config    *config.Manager
+	Cache     *cache.Manager
+	RateLimit *ratelimit.Manager
+	Memory    *memory.Memory
+	Quota     *quota.Tracker
+	Retry     retry.Policies
+	Breaker   *breaker.Breaker
+	Latency   *latency.Tracker
+	Services  []utils.ServiceConfig
+	Client    *translate.Client
+	// CacheErr says why the cache lives in memory only although it is
+	// enabled; it is nil when the cache is persistent or disabled.
+	CacheErr error
+}
+
+func Load() *Core {
+	configManager := config.NewManager()
+	if err := configManager.Initialize(); err != nil {
+		_ = err
+	}
+
+	var services []utils.ServiceConfig
+	if configServices := configManager.GetEnabledProviders(); len(configServices) > 0 {
+		services = configServices
+	} else {
+		services = config.GetAvailableServices()
+	}
+
+	settings := configManager.GetSettings()
+
+	var cacheManager *cache.Manager
+	var cacheErr error
+	if settings.CacheEnabled {
+		cacheManager, cacheErr = openCache(cacheOptions(configManager))
+	}
+	rateLimitManager := newRateLimiter(configManager)
+	translationMemory := openMemory(settings.TMThreshold)
+	quotaTracker := openQuota(configManager)
+	circuitBreaker := newBreaker(settings.CircuitBreaker)
+	latencyTracker := newLatencyTracker()
+
+	return &Core{
+		Config:    configManager,
+		Cache:     cacheManager,
+		RateLimit: rateLimitManager,
+		Memory:    translationMemory,
+		Quota:     quotaTracker,
+		Retry:     retryPolicies(configManager),
+		Breaker:   circuitBreaker,
+		Latency:   latencyTracker,
+		Services:  services,
+		CacheErr:  cacheErr,
+		Client: translate.New(
+			translate.WithProviders(services),
+			translate.WithCache(cacheManager),
+			translate.WithRateLimiter(rateLimitManager),
+			translate.WithKeySource(configManager),
+			translate.WithMemory(translationMemory),
+			translate.WithQuota(quotaTracker),
+			translate.WithBreaker(circuitBreaker),
+			translate.WithLatencyTracker(latencyTracker),
+			translate.WithHedging(settings.Hedging == nil || *settings.Hedging),
+			translate.WithPostProcessing(postProcessing(configManager)),
+			translate.WithStrategy(translate.Strategy(settings.Strategy), settings.Quorum),
+			translate.WithConcurrency(
+				defaultInt(settings.Concurrency.Global, pool.DefaultGlobal),
+				defaultInt(settings.Concurrency.PerProvider, pool.DefaultPerProvider),
+			),
+		),
+	}
+}
+
+// UsableProviders returns the enabled providers that have every credential
+// they need and quota left.
+func (c *Core) UsableProviders() []string {
+	var names []string
+	for _, svc := range c.Services {
+		if c.Config.IsAPIKeyRequired(svc.Name) && c.Config.GetAPIKey(svc.Name) == "" {
+			continue
+		}
+		if c.Quota.Exhausted(svc.Name) {
+			continue
+		}
+		names = append(names, svc.Name)
+	}
+	return names
+}
+
+// openCache prefers the persistent cache. When the cache directory is
+// unusable it returns an in-memory cache together with the reason.
+func openCache(options cache.Options) (*cache.Manager, error) {
+	dir, err := cache.DefaultDir()
+	if err == nil {
+		var persistent *cache.Manager
+		if persistent, err = cache.NewPersistentManager(dir, options); err == nil {
+			return persistent, nil
+		}
+	}
+	return cache.NewManagerWithOptions(options), fmt.Errorf("cache is not saved to disk: %w", err)
+}
+
+func cacheOptions(configManager *config.Manager) cache.Options {
+	settings := configManager.GetSettings().Cache
+	options := cache.DefaultOptions()
+
+	if settings.MaxEntries > 0 {
+		options.MaxEntries = settings.MaxEntries
+	}
+	if settings.MaxBytes > 0 {
+		options.MaxBytes = settings.MaxBytes
+	}
+	if ttl, err := time.ParseDuration(settings.TTL); err == nil {
+		options.TTL = ttl
+	}
+	options.ProviderTTL = configManager.GetCacheTTLs()
+
+	return options
+}
+
+// newRateLimiter shares its buckets with other translatego processes through
+// the state directory when it is usable.
+func newRateLimiter(configManager *config.Manager) *ratelimit.Manager {
+	manager := ratelimit.NewManager()
+	if dir, err := state.DefaultDir(); err == nil {
+		if shared, err := ratelimit.NewSharedManager(dir); err == nil {
+			manager = shared
+		}
+	}
+
+	for name, override := range configManager.GetRateLimits() {
+		limits := ratelimit.DefaultLimits(name)
+		if override.Requests > 0 {
+			limits.Requests = override.Requests
+		}
+		if override.Characters > 0 {
+			limits.Characters = override.Characters
+		}
+		if interval, err := time.ParseDuration(override.Interval); err == nil && interval > 0 {
+			limits.Interval = interval
+		}
+		if override.Burst > 0 {
+			limits.Burst = override.Burst
+		}
+		manager.SetLimits(name, limits)
+	}
+
+	return manager
+}
+
+// newBreaker shares circuits with other translatego processes through the
+// state directory when it is usable.
+func newBreaker(bc *config.BreakerConfig) *breaker.Breaker {
+	options := breaker.DefaultOptions()
+	if bc != nil {
+		if bc.FailureThreshold > 0 {
+			options.FailureThreshold = bc.FailureThreshold
+		}
+		if cooldown, err := time.ParseDuration(bc.Cooldown); err == nil && cooldown > 0 {
+			options.Cooldown = cooldown
+		}
+		if len(bc.ErrorTypes) > 0 {
+			options.ErrorTypes = make(map[string]bool)
+			for _, errorType := range bc.ErrorTypes {
+				options.ErrorTypes[errorType] = true
+			}
+		}
+	}
+
+	if dir, err := state.DefaultDir(); err == nil {
+		if shared, err := breaker.NewShared(dir, options); err == nil {
+			return shared
+		}
+	}
+	return breaker.New(options)
+}
+
+// newLatencyTracker keeps latencies in the state directory when it is usable,
+// so that hedging works from the first request of a CLI run.
+func newLatencyTracker() *latency.Tracker {
+	if dir, err := state.DefaultDir(); err == nil {
+		if shared, err := latency.NewShared(dir); err == nil {
+			return shared
+		}
+	}
+	return latency.New()
+}
+
+// postProcessing parses the configured cleanup steps; providers with an
+// invalid list keep the built-in steps.
+func postProcessing(configManager *config.Manager) map[string]translate.PostProcessing {
+	pipelines := make(map[string]translate.PostProcessing)
+	for name, steps := range configManager.GetPostProcessing() {
+		if pipeline, err := translate.ParsePostProcessing(steps); err == nil {
+			pipelines[name] = pipeline
+		}
+	}
+	return pipelines
+}
+
+func openMemory(threshold float64) *memory.Memory {
+	dir, err := memory.DefaultDir()
+	if err == nil {
+		var persistent *memory.Memory
+		if persistent, err = memory.Open(dir, threshold); err == nil {
+			return persistent
+		}
+	}
+	return memory.New(threshold)
+}
+
+// openQuota prefers the persistent usage state and falls back to counting in
+// memory when the state directory is unusable. Configured caps replace the
+// built-in ones of a provider as a whole.
+func openQuota(configManager *config.Manager) *quota.Tracker {
+	limits := make(map[string]quota.Limits)
+	for name, override := range configManager.GetQuotas() {
+		provider := quota.DefaultLimits(name)
+		configured := quota.Limits{
+			DailyCharacters:   override.DailyCharacters,
+			MonthlyCharacters: override.MonthlyCharacters,
+			DailyTokens:       override.DailyTokens,
+			MonthlyTokens:     override.MonthlyTokens,
+			WarnAt:            provider.WarnAt,
+		}
+		if !configured.IsZero() {
+			provider = configured
+		}
+		if override.WarnAt > 0 {
+			provider.WarnAt = override.WarnAt
+		}
+		limits[name] = provider
+	}
+
+	dir, err := state.DefaultDir()
+	if err == nil {
+		var persistent *quota.Tracker
+		if persistent, err = quota.Open(dir, limits); err == nil {
+			return persistent
+		}
+	}
+	return quota.New(limits)
+}
+
+// retryPolicies starts from settings.max_retries and applies the global and
+// per-provider retry sections of the config.
+func retryPolicies(configManager *config.Manager) retry.Policies {
+	settings := configManager.GetSettings()
+	policies := retry.DefaultPolicies(settings.MaxRetries)
+
+	if settings.Retry != nil {
+		policies.Default = policies.Default.With(retryOverride(*settings.Retry))
+		for errorType, override := range settings.Retry.ErrorTypes {
+			policies.ErrorTypes[errorType] = policies.ErrorTypes[errorType].Merge(retryOverride(override))
+		}
+	}
+
+	for name, providerConfig := range configManager.GetRetryConfigs() {
+		providerOverride := retry.ProviderOverride{
+			Override:   retryOverride(providerConfig),
+			ErrorTypes: make(map[string]retry.Override),
+		}
+		for errorType, override := range providerConfig.ErrorTypes {
+			providerOverride.ErrorTypes[errorType] = retryOverride(override)
+		}
+		policies.Providers[name] = providerOverride
+	}
+
+	return policies
+}
+
+func retryOverride(rc config.RetryConfig) retry.Override {
+	override := retry.Override{
+		MaxAttempts: rc.MaxAttempts,
+		Jitter:      rc.Jitter,
+	}
+	if base, err := time.ParseDuration(rc.Base); err == nil {
+		override.Base = &base
+	}
+	if maximum, err := time.ParseDuration(rc.Max); err == nil {
+		override.Max = &maximum
+	}
+	if rc.Multiplier > 0 {
+		override.Multiplier = &rc.Multiplier
+	}
+	return override
+}
+
+func defaultInt(value, fallback int) int {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func (c *Core) Close() error {
+	var err error
+	if c.Cache != nil {
+		err = c.Cache.Close()
+	}
+	if memErr := c.Memory.Close(); err == nil {
+		err = memErr
+	}
+	return err
+}
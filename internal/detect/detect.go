@@ -0,0 +1,308 @@
+// Package detect guesses the language of a text offline, from the scripts it
+// is written in and, where several languages share a script, from character
+// n-gram profiles built from the embedded sample texts.
+package detect
+
+import (
+	"embed"
+	"math"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+//go:embed profiles/*.txt
+var profileFiles embed.FS
+
+const (
+	// maxGram is the longest character n-gram in a profile.
+	maxGram = 3
+	// profileSize is how many of the most frequent n-grams are ranked, for
+	// languages and for the text alike.
+	profileSize = 400
+	// temperature sets how fast confidence drops as a language's distance to
+	// the text grows beyond the best one's.
+	temperature = 0.025
+	// shortText is the letter count at which a ranked guess is held to half
+	// confidence; a handful of letters says little about the language.
+	shortText = 8
+	// markerPenalty is added to the distance of a language for every marker
+	// letter in the text that the language does not use.
+	markerPenalty = 0.03
+)
+
+// Candidate is a possible language of a text with the detector's confidence
+// in it, from 0 to 1.
+type Candidate struct {
+	Language   string
+	Confidence float64
+}
+
+var kana = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x3040, Hi: 0x309f, Stride: 1}, // Hiragana
+		{Lo: 0x30a0, Hi: 0x30ff, Stride: 1}, // Katakana
+		{Lo: 0x31f0, Hi: 0x31ff, Stride: 1}, // Katakana phonetic extensions
+		{Lo: 0xff66, Hi: 0xff9d, Stride: 1}, // Halfwidth Katakana
+	},
+}
+
+// scripts lists the supported languages by the script they are written in.
+// Languages sharing a script need a profile in profiles/<code>.txt.
+var scripts = []struct {
+	table     *unicode.RangeTable
+	languages []string
+}{
+	{unicode.Latin, []string{
+		"en", "de", "fr", "es", "it", "pt", "nl", "pl", "tr",
+		"sv", "da", "no", "fi", "cs", "hu", "ro", "vi", "id",
+	}},
+	{unicode.Cyrillic, []string{"ru", "uk", "bg"}},
+	{unicode.Greek, []string{"el"}},
+	{unicode.Arabic, []string{"ar", "fa"}},
+	{unicode.Hebrew, []string{"he"}},
+	{unicode.Devanagari, []string{"hi"}},
+	{unicode.Thai, []string{"th"}},
+	{unicode.Hangul, []string{"ko"}},
+	{kana, []string{"ja"}},
+	{unicode.Han, []string{"zh"}},
+}
+
+// markers are letters only some languages sharing a script use. They tell
+// close languages apart in texts too short for the profiles to.
+var markers = map[rune][]string{
+	'ß': {"de"}, 'ä': {"de", "sv", "fi"}, 'ö': {"de", "sv", "fi", "tr", "hu"},
+	'ü': {"de", "tr", "hu"}, 'å': {"sv", "da", "no"}, 'æ': {"da", "no"}, 'ø': {"da", "no"},
+	'ñ': {"es"}, 'ł': {"pl"}, 'ą': {"pl"}, 'ę': {"pl"}, 'ś': {"pl"}, 'ź': {"pl"},
+	'ż': {"pl"}, 'ń': {"pl"}, 'ć': {"pl"}, 'ő': {"hu"}, 'ű': {"hu"},
+	'ğ': {"tr"}, 'ı': {"tr"}, 'ş': {"tr", "ro"}, 'ș': {"ro"}, 'ț': {"ro"},
+	'ă': {"ro", "vi"}, 'î': {"ro", "fr"}, 'ř': {"cs"}, 'ů': {"cs"}, 'ě': {"cs"},
+	'ď': {"cs"}, 'ť': {"cs"}, 'ň': {"cs"}, 'č': {"cs"}, 'š': {"cs"}, 'ž': {"cs"},
+	'ã': {"pt", "vi"}, 'õ': {"pt", "vi"}, 'ç': {"fr", "pt", "tr"}, 'œ': {"fr"},
+	'û': {"fr"}, 'ë': {"fr", "nl"}, 'ï': {"fr", "nl"}, 'è': {"fr", "it", "vi"},
+	'ù': {"fr", "it", "vi"}, 'ò': {"it", "vi"}, 'ì': {"it", "vi"},
+	'đ': {"vi"}, 'ơ': {"vi"}, 'ư': {"vi"},
+	'і': {"uk"}, 'ї': {"uk"}, 'є': {"uk"}, 'ґ': {"uk"},
+	'ы': {"ru"}, 'э': {"ru"}, 'ё': {"ru"}, 'ъ': {"ru", "bg"},
+	'پ': {"fa"}, 'چ': {"fa"}, 'ژ': {"fa"}, 'گ': {"fa"}, 'ک': {"fa"}, 'ی': {"fa"},
+	'ة': {"ar"}, 'ي': {"ar"}, 'ك': {"ar"}, 'ى': {"ar"},
+}
+
+// vietnamese covers the letters with two diacritics, such as ộ and ữ, that
+// only Vietnamese uses.
+var vietnamese = &unicode.RangeTable{
+	R16: []unicode.Range16{{Lo: 0x1ea0, Hi: 0x1ef9, Stride: 1}},
+}
+
+// markerLanguages returns, for every distinct marker letter in text, the
+// languages that use it.
+func markerLanguages(text string) [][]string {
+	seen := make(map[rune]bool)
+	var found [][]string
+	for _, r := range strings.ToLower(text) {
+		if unicode.Is(vietnamese, r) {
+			r = 'ơ'
+		}
+		if languages, exists := markers[r]; exists && !seen[r] {
+			seen[r] = true
+			found = append(found, languages)
+		}
+	}
+	return found
+}
+
+// Languages returns the codes of every language the detector knows.
+func Languages() []string {
+	var codes []string
+	for _, script := range scripts {
+		codes = append(codes, script.languages...)
+	}
+	return codes
+}
+
+// Detect returns the candidate languages of text, most likely first. It
+// returns nothing for text without letters. Confidence in languages told apart
+// by their profiles is held down for short texts, so the candidates may add up
+// to less than 1.
+func Detect(text string) []Candidate {
+	counts := make([]int, len(scripts))
+	letters := 0
+	hasKana := false
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		for i, script := range scripts {
+			if unicode.Is(script.table, r) {
+				counts[i]++
+				letters++
+				hasKana = hasKana || script.table == kana
+				break
+			}
+		}
+	}
+	if letters == 0 {
+		return nil
+	}
+
+	confidence := make(map[string]float64)
+	for i, script := range scripts {
+		if counts[i] == 0 {
+			continue
+		}
+		share := float64(counts[i]) / float64(letters)
+
+		languages := script.languages
+		// Japanese mixes kanji with kana; Han alone is taken as Chinese.
+		if script.table == unicode.Han && hasKana {
+			languages = []string{"ja"}
+		}
+
+		for language, p := range rank(text, languages, counts[i]) {
+			confidence[language] += share * p
+		}
+	}
+
+	candidates := make([]Candidate, 0, len(confidence))
+	for language, c := range confidence {
+		candidates = append(candidates, Candidate{Language: language, Confidence: c})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Confidence != candidates[j].Confidence {
+			return candidates[i].Confidence > candidates[j].Confidence
+		}
+		return candidates[i].Language < candidates[j].Language
+	})
+	return candidates
+}
+
+// Best returns the most likely language of text and the confidence in it, or
+// an empty language for text without letters.
+func Best(text string) (string, float64) {
+	candidates := Detect(text)
+	if len(candidates) == 0 {
+		return "", 0
+	}
+	return candidates[0].Language, candidates[0].Confidence
+}
+
+var (
+	profiles     map[string]map[string]int // n-gram rank by language
+	profilesOnce sync.Once
+)
+
+func loadProfiles() {
+	profiles = make(map[string]map[string]int)
+
+	entries, _ := profileFiles.ReadDir("profiles")
+	for _, entry := range entries {
+		data, err := profileFiles.ReadFile(path.Join("profiles", entry.Name()))
+		if err != nil {
+			continue
+		}
+		profiles[strings.TrimSuffix(entry.Name(), ".txt")] = ranks(string(data))
+	}
+}
+
+// ranks orders the profileSize most frequent n-grams of text, most frequent
+// first.
+func ranks(text string) map[string]int {
+	counts := make(map[string]int)
+	for _, gram := range grams(text) {
+		counts[gram]++
+	}
+
+	ordered := make([]string, 0, len(counts))
+	for gram := range counts {
+		ordered = append(ordered, gram)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if counts[ordered[i]] != counts[ordered[j]] {
+			return counts[ordered[i]] > counts[ordered[j]]
+		}
+		return ordered[i] < ordered[j]
+	})
+
+	ranked := make(map[string]int, min(len(ordered), profileSize))
+	for i, gram := range ordered[:min(len(ordered), profileSize)] {
+		ranked[gram] = i
+	}
+	return ranked
+}
+
+// rank spreads a probability over languages sharing a script by the
+// out-of-place distance between the n-gram ranks of the text and of each
+// language's profile (Cavnar and Trenkle). letters is how many letters of the
+// text are in the script; fewer letters mean less confidence overall.
+func rank(text string, languages []string, letters int) map[string]float64 {
+	if len(languages) == 1 {
+		return map[string]float64{languages[0]: 1}
+	}
+	profilesOnce.Do(loadProfiles)
+
+	textRanks := ranks(text)
+	textMarkers := markerLanguages(text)
+	distances := make(map[string]float64, len(languages))
+	best := math.Inf(1)
+	for _, language := range languages {
+		profile := profiles[language]
+		distance := 0
+		for gram, textRank := range textRanks {
+			if profileRank, exists := profile[gram]; exists {
+				distance += abs(textRank - profileRank)
+			} else {
+				distance += profileSize
+			}
+		}
+		// 0 when the ranks agree, 1 when no n-gram is in the profile.
+		distances[language] = float64(distance) / float64(len(textRanks)*profileSize)
+		for _, users := range textMarkers {
+			if !slices.Contains(users, language) {
+				distances[language] += markerPenalty
+			}
+		}
+		best = min(best, distances[language])
+	}
+
+	scores := make(map[string]float64, len(languages))
+	sum := 0.0
+	for language, distance := range distances {
+		scores[language] = math.Exp((best - distance) / temperature)
+		sum += scores[language]
+	}
+	certainty := float64(letters) / float64(letters+shortText)
+	for language := range scores {
+		scores[language] *= certainty / sum
+	}
+	return scores
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// grams returns the 1- to maxGram-character n-grams of every word of text,
+// with spaces marking the word boundaries.
+func grams(text string) []string {
+	var out []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		runes := []rune(" " + word + " ")
+		for n := 1; n <= maxGram; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				gram := string(runes[i : i+n])
+				if gram != " " {
+					out = append(out, gram)
+				}
+			}
+		}
+	}
+	return out
+}
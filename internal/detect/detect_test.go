@@ -0,0 +1,81 @@
+package detect
+
+import (
+	"slices"
+	"testing"
+
+	"translatego/internal/languages"
+)
+
+func TestLanguagesCoverRegistry(t *testing.T) {
+	known := Languages()
+	for _, tag := range languages.Tags() {
+		if !slices.Contains(known, languages.Base(tag)) {
+			t.Errorf("no script rule or profile for %s", tag)
+		}
+	}
+}
+
+// TestDetect expects a confident, correct answer for every sentence except
+// the Danish one: written Danish and Norwegian Bokmål share most of their
+// words, so it only has to avoid a confident wrong answer.
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		want string
+		text string
+	}{
+		{"en", "Tonight we are going to the theatre with our friends, if it does not rain."},
+		{"de", "Heute Abend gehen wir mit unseren Freunden ins Theater, wenn es nicht regnet."},
+		{"fr", "Ce soir, nous allons au théâtre avec nos amis, s'il ne pleut pas."},
+		{"es", "Esta noche vamos al teatro con nuestros amigos, si no llueve."},
+		{"it", "Stasera andiamo a teatro con i nostri amici, se non piove."},
+		{"pt", "Hoje à noite vamos ao teatro com os amigos, se não chover."},
+		{"nl", "Vanavond gaan we samen met vrienden naar het theater, als het niet regent."},
+		{"pl", "Dzisiaj wieczorem pójdziemy do teatru razem z przyjaciółmi, jeśli nie będzie padać."},
+		{"tr", "Bu akşam yağmur yağmazsa arkadaşlarımızla tiyatroya gideceğiz."},
+		{"sv", "I kväll går vi på teater med våra vänner, om det inte regnar."},
+		{"da", "I aften går vi i teatret med vores venner, hvis det ikke regner."},
+		{"no", "I kveld går vi på teater med vennene våre, hvis det ikke regner."},
+		{"fi", "Tänä iltana menemme teatteriin ystäviemme kanssa, jos ei sada."},
+		{"cs", "Dnes večer půjdeme s přáteli do divadla, pokud nebude pršet."},
+		{"hu", "Ma este a barátainkkal színházba megyünk, ha nem esik az eső."},
+		{"ro", "Diseară mergem la teatru cu prietenii noștri, dacă nu plouă."},
+		{"vi", "Tối nay chúng tôi sẽ đi xem kịch với bạn bè nếu trời không mưa."},
+		{"id", "Malam ini kami akan pergi ke teater bersama teman-teman kalau tidak hujan."},
+		{"ru", "Сегодня вечером мы пойдём в театр вместе с друзьями, если не будет дождя."},
+		{"uk", "Сьогодні ввечері ми підемо до театру разом із друзями, якщо не буде дощу."},
+		{"bg", "Тази вечер ще отидем на театър с приятелите си, ако не вали."},
+		{"el", "Απόψε θα πάμε στο θέατρο με τους φίλους μας, αν δεν βρέχει."},
+		{"ar", "سنذهب الليلة إلى المسرح مع أصدقائنا إذا لم تمطر."},
+		{"fa", "امشب اگر باران نبارد با دوستانمان به تئاتر می‌رویم."},
+		{"he", "הערב נלך לתיאטרון עם החברים שלנו, אם לא ירד גשם."},
+		{"hi", "अगर बारिश नहीं हुई तो आज रात हम अपने दोस्तों के साथ थिएटर जाएंगे।"},
+		{"th", "คืนนี้เราจะไปดูละครกับเพื่อนถ้าฝนไม่ตก"},
+		{"ko", "비가 오지 않으면 오늘 밤 친구들과 극장에 갈 거예요."},
+		{"ja", "雨が降らなければ、今夜友達と劇場に行きます。"},
+		{"zh", "如果不下雨，今晚我们和朋友去剧院。"},
+	}
+
+	for _, tt := range tests {
+		language, confidence := Best(tt.text)
+		if language != tt.want && confidence >= 0.6 || tt.want != "da" && confidence < 0.6 {
+			t.Errorf("Best(%q) = %s %.2f, want %s", tt.text, language, confidence, tt.want)
+		}
+	}
+}
+
+// Short texts shared by several languages must not come back as a confident
+// wrong guess.
+func TestDetectShortTextIsUnsure(t *testing.T) {
+	for _, text := range []string{"Hello", "Gara", "Hotel", "Taxi", "OK"} {
+		if language, confidence := Best(text); confidence >= 0.6 {
+			t.Errorf("Best(%q) = %s %.2f, want confidence below 0.6", text, language, confidence)
+		}
+	}
+}
+
+func TestDetectNoLetters(t *testing.T) {
+	if candidates := Detect("123 !? 42"); candidates != nil {
+		t.Errorf("Detect of digits = %v, want nil", candidates)
+	}
+}
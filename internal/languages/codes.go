@@ -0,0 +1,63 @@
+package languages
+
+import "strings"
+
+// codeTable maps registry tags to the codes a provider expects.
+type codeTable struct {
+	codes      map[string]string // Codes that differ from the tag, by tag
+	variants   bool              // Region and script variants are sent as is
+	upper      bool              // Codes are upper case
+	sourceBase bool              // Source languages take no variants
+}
+
+var reversoCodes = map[string]string{
+	"ar": "ara", "zh": "chi", "nl": "dut", "en": "eng", "fr": "fra", "de": "ger",
+	"he": "heb", "it": "ita", "ja": "jpn", "ko": "kor", "pl": "pol", "pt": "por",
+	"ro": "rum", "ru": "rus", "es": "spa", "sv": "swe", "tr": "tur", "uk": "ukr",
+}
+
+var providerCodes = map[string]codeTable{
+	"GOOGLE": {codes: map[string]string{"zh-Hans": "zh-CN", "zh-Hant": "zh-TW", "pt-PT": "pt-PT"}},
+	"LINGVA": {codes: map[string]string{"zh-Hant": "zh_HANT"}},
+	"MYMEMORY": {
+		codes:    map[string]string{"zh-Hans": "zh-CN", "zh-Hant": "zh-TW"},
+		variants: true,
+	},
+	"DEEPL":    {variants: true, upper: true, sourceBase: true},
+	"REVERSO":  {codes: reversoCodes},
+	"REVERSO2": {codes: reversoCodes},
+}
+
+// Code returns the code the provider expects for tag as the target language.
+// Providers without a table, such as the LLMs, get the canonical tag.
+func Code(serviceName, tag string) string {
+	tag = Canonical(tag)
+	table, exists := providerCodes[serviceName]
+	if !exists {
+		return tag
+	}
+	if code, exists := table.codes[tag]; exists {
+		return code
+	}
+
+	code := tag
+	if !table.variants {
+		code = Base(tag)
+		if mapped, exists := table.codes[code]; exists {
+			return mapped
+		}
+	}
+	if table.upper {
+		code = strings.ToUpper(code)
+	}
+	return code
+}
+
+// SourceCode returns the code the provider expects for tag as the source
+// language.
+func SourceCode(serviceName, tag string) string {
+	if providerCodes[serviceName].sourceBase {
+		tag = Base(tag)
+	}
+	return Code(serviceName, tag)
+}
@@ -0,0 +1,179 @@
+// Package languages is the registry of languages translatego knows, keyed by
+// BCP-47 tag, and of the codes each provider uses for them.
+package languages
+
+import (
+	"strings"
+	"unicode"
+)
+
+type Direction string
+
+const (
+	LTR Direction = "ltr"
+	RTL Direction = "rtl"
+)
+
+// Language describes one registry entry. Script is an ISO 15924 code; ISO6392
+// is the bibliographic ISO 639-2 code and ISO6393 the ISO 639-3 code of the
+// base language.
+type Language struct {
+	Tag       string
+	English   string
+	Native    string
+	ISO6392   string
+	ISO6393   string
+	Script    string
+	Direction Direction
+}
+
+// registry is in display order: the languages offered before the rest, then
+// alphabetically by tag, with variants after their base language.
+var registry = []Language{
+	{"ru", "Russian", "Русский", "rus", "rus", "Cyrl", LTR},
+	{"en", "English", "English", "eng", "eng", "Latn", LTR},
+	{"de", "German", "Deutsch", "ger", "deu", "Latn", LTR},
+	{"fr", "French", "Français", "fre", "fra", "Latn", LTR},
+	{"es", "Spanish", "Español", "spa", "spa", "Latn", LTR},
+	{"it", "Italian", "Italiano", "ita", "ita", "Latn", LTR},
+	{"ja", "Japanese", "日本語", "jpn", "jpn", "Jpan", LTR},
+	{"zh", "Chinese", "中文", "chi", "zho", "Hans", LTR},
+	{"ko", "Korean", "한국어", "kor", "kor", "Kore", LTR},
+	{"ar", "Arabic", "العربية", "ara", "ara", "Arab", RTL},
+	{"en-GB", "English (UK)", "English (UK)", "eng", "eng", "Latn", LTR},
+	{"en-US", "English (US)", "English (US)", "eng", "eng", "Latn", LTR},
+	{"zh-Hans", "Chinese (Simplified)", "简体中文", "chi", "zho", "Hans", LTR},
+	{"zh-Hant", "Chinese (Traditional)", "繁體中文", "chi", "zho", "Hant", LTR},
+	{"bg", "Bulgarian", "Български", "bul", "bul", "Cyrl", LTR},
+	{"cs", "Czech", "Čeština", "cze", "ces", "Latn", LTR},
+	{"da", "Danish", "Dansk", "dan", "dan", "Latn", LTR},
+	{"el", "Greek", "Ελληνικά", "gre", "ell", "Grek", LTR},
+	{"fa", "Persian", "فارسی", "per", "fas", "Arab", RTL},
+	{"fi", "Finnish", "Suomi", "fin", "fin", "Latn", LTR},
+	{"he", "Hebrew", "עברית", "heb", "heb", "Hebr", RTL},
+	{"hi", "Hindi", "हिन्दी", "hin", "hin", "Deva", LTR},
+	{"hu", "Hungarian", "Magyar", "hun", "hun", "Latn", LTR},
+	{"id", "Indonesian", "Bahasa Indonesia", "ind", "ind", "Latn", LTR},
+	{"nl", "Dutch", "Nederlands", "dut", "nld", "Latn", LTR},
+	{"no", "Norwegian", "Norsk", "nor", "nor", "Latn", LTR},
+	{"pl", "Polish", "Polski", "pol", "pol", "Latn", LTR},
+	{"pt", "Portuguese", "Português", "por", "por", "Latn", LTR},
+	{"pt-BR", "Portuguese (Brazil)", "Português (Brasil)", "por", "por", "Latn", LTR},
+	{"pt-PT", "Portuguese (Portugal)", "Português (Portugal)", "por", "por", "Latn", LTR},
+	{"ro", "Romanian", "Română", "rum", "ron", "Latn", LTR},
+	{"sv", "Swedish", "Svenska", "swe", "swe", "Latn", LTR},
+	{"th", "Thai", "ไทย", "tha", "tha", "Thai", LTR},
+	{"tr", "Turkish", "Türkçe", "tur", "tur", "Latn", LTR},
+	{"uk", "Ukrainian", "Українська", "ukr", "ukr", "Cyrl", LTR},
+	{"vi", "Vietnamese", "Tiếng Việt", "vie", "vie", "Latn", LTR},
+}
+
+var byTag = func() map[string]Language {
+	m := make(map[string]Language, len(registry))
+	for _, language := range registry {
+		m[language.Tag] = language
+	}
+	return m
+}()
+
+// All returns every language in the registry, in display order.
+func All() []Language {
+	return append([]Language(nil), registry...)
+}
+
+// Tags returns the tag of every language in the registry, in display order.
+func Tags() []string {
+	tags := make([]string, len(registry))
+	for i, language := range registry {
+		tags[i] = language.Tag
+	}
+	return tags
+}
+
+// Canonical returns tag with the usual BCP-47 casing and hyphens, so that
+// "pt_br" and "ZH-hans" become "pt-BR" and "zh-Hans".
+func Canonical(tag string) string {
+	subtags := strings.FieldsFunc(strings.TrimSpace(tag), func(r rune) bool {
+		return r == '-' || r == '_'
+	})
+	for i, subtag := range subtags {
+		switch {
+		case i == 0:
+			subtags[i] = strings.ToLower(subtag)
+		case len(subtag) == 4 && isLetters(subtag):
+			subtags[i] = strings.ToUpper(subtag[:1]) + strings.ToLower(subtag[1:])
+		case len(subtag) == 2 && isLetters(subtag):
+			subtags[i] = strings.ToUpper(subtag)
+		default:
+			subtags[i] = strings.ToLower(subtag)
+		}
+	}
+	return strings.Join(subtags, "-")
+}
+
+// Lookup returns the registry entry for tag. A tag missing from the registry
+// falls back to its base language, so "en-AU" finds English.
+func Lookup(tag string) (Language, bool) {
+	tag = Canonical(tag)
+	if language, exists := byTag[tag]; exists {
+		return language, true
+	}
+	language, exists := byTag[Base(tag)]
+	return language, exists
+}
+
+// Base returns the language subtag of tag: "zh" for "zh-Hant".
+func Base(tag string) string {
+	base, _, _ := strings.Cut(Canonical(tag), "-")
+	return base
+}
+
+// EnglishName returns the English name of tag, or tag itself if unknown.
+func EnglishName(tag string) string {
+	if language, exists := Lookup(tag); exists {
+		return language.English
+	}
+	return tag
+}
+
+// NativeName returns the name of tag in that language, or tag itself if
+// unknown.
+func NativeName(tag string) string {
+	if language, exists := Lookup(tag); exists {
+		return language.Native
+	}
+	return tag
+}
+
+var scriptTables = map[string][]*unicode.RangeTable{
+	"Latn": {unicode.Latin},
+	"Cyrl": {unicode.Cyrillic},
+	"Grek": {unicode.Greek},
+	"Arab": {unicode.Arabic},
+	"Hebr": {unicode.Hebrew},
+	"Deva": {unicode.Devanagari},
+	"Thai": {unicode.Thai},
+	"Kore": {unicode.Hangul},
+	"Hans": {unicode.Han},
+	"Hant": {unicode.Han},
+	"Jpan": {unicode.Han, unicode.Hiragana, unicode.Katakana},
+}
+
+// ScriptTables returns the Unicode tables covering the letters text in the
+// language is written with, or nil for an unknown tag.
+func ScriptTables(tag string) []*unicode.RangeTable {
+	language, exists := Lookup(tag)
+	if !exists {
+		return nil
+	}
+	return scriptTables[language.Script]
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package latency
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"translatego/internal/state"
+)
+
+const (
+	stateFileName = "latency.json"
+	// Window is how many recent requests per provider are kept.
+	Window = 50
+	// MinSamples is how many requests a provider needs before its
+	// percentiles are trusted.
+	MinSamples = 10
+)
+
+// Tracker records how long successful requests to each provider took.
+type Tracker struct {
+	samples map[string][]time.Duration
+	path    string
+	mu      sync.Mutex
+}
+
+func New() *Tracker {
+	return &Tracker{samples: make(map[string][]time.Duration)}
+}
+
+// NewShared returns a tracker whose samples live in a state file in dir, so
+// that short-lived CLI runs learn from each other.
+func NewShared(dir string) (*Tracker, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	t := New()
+	t.path = filepath.Join(dir, stateFileName)
+	return t, nil
+}
+
+func (t *Tracker) Observe(provider string, took time.Duration) {
+	t.update(func() {
+		samples := append(t.samples[provider], took)
+		if len(samples) > Window {
+			samples = samples[len(samples)-Window:]
+		}
+		t.samples[provider] = samples
+	})
+}
+
+// Percentile returns the p-th percentile (0 to 1) of the provider's recent
+// latencies, or false while there are fewer than MinSamples of them.
+func (t *Tracker) Percentile(provider string, p float64) (time.Duration, bool) {
+	var samples []time.Duration
+	t.view(func() {
+		samples = slices.Clone(t.samples[provider])
+	})
+	if len(samples) < MinSamples {
+		return 0, false
+	}
+
+	slices.Sort(samples)
+	index := int(p*float64(len(samples))+0.5) - 1
+	return samples[min(max(index, 0), len(samples)-1)], true
+}
+
+// update runs fn against the shared samples when there is a state file, and
+// against the in-process ones otherwise or when the file is unusable.
+func (t *Tracker) update(fn func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.path == "" {
+		fn()
+		return
+	}
+
+	samples := make(map[string][]time.Duration)
+	if ran, err := state.Update(t.path, &samples, func() {
+		t.samples = samples
+		fn()
+	}); err != nil && !ran {
+		fn()
+	}
+}
+
+// view is update for fn that only reads the samples.
+func (t *Tracker) view(fn func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.path != "" {
+		samples := make(map[string][]time.Duration)
+		if err := state.Load(t.path, &samples); err == nil {
+			t.samples = samples
+		}
+	}
+	fn()
+}
@@ -0,0 +1,379 @@
+package memory
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"translatego/internal/languages"
+	"translatego/internal/state"
+)
+
+const (
+	fileName         = "memory.jsonl"
+	DefaultThreshold = 0.75
+)
+
+type Segment struct {
+	Source     string    `json:"source"`
+	Target     string    `json:"target"`
+	SourceLang string    `json:"source_lang"`
+	TargetLang string    `json:"target_lang"`
+	Provider   string    `json:"provider,omitempty"`
+	Created    time.Time `json:"created"`
+}
+
+// record is one line of memory.jsonl. A record with Deleted set removes the
+// segment an earlier line added.
+type record struct {
+	Segment
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+type Match struct {
+	Segment
+	Score float64
+}
+
+// Memory is a translation memory: source/target segment pairs searched by
+// similarity rather than exact key.
+type Memory struct {
+	segments  []Segment
+	index     map[string]int
+	threshold float64
+	path      string
+	file      *os.File
+	mu        sync.RWMutex
+}
+
+// DefaultDir returns $XDG_DATA_HOME/translatego, falling back to
+// ~/.local/share/translatego, or %LOCALAPPDATA%\translatego on Windows.
+func DefaultDir() (string, error) {
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+			return filepath.Join(dir, "translatego"), nil
+		}
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "translatego"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate data directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", "translatego"), nil
+}
+
+func New(threshold float64) *Memory {
+	if threshold <= 0 || threshold > 1 {
+		threshold = DefaultThreshold
+	}
+	return &Memory{
+		index:     make(map[string]int),
+		threshold: threshold,
+	}
+}
+
+// Open loads the memory stored in dir and appends new segments to it.
+func Open(dir string, threshold float64) (*Memory, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create memory directory: %w", err)
+	}
+
+	m := New(threshold)
+	path := filepath.Join(dir, fileName)
+
+	if file, err := os.Open(path); err == nil {
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			var rec record
+			if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+				continue
+			}
+			if rec.Deleted {
+				m.remove(canonicalLangs(rec.Segment))
+			} else {
+				m.insert(canonicalLangs(rec.Segment))
+			}
+		}
+		file.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read memory: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to open memory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open memory: %w", err)
+	}
+	m.path = path
+	m.file = file
+
+	return m, nil
+}
+
+func (m *Memory) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.file == nil {
+		return nil
+	}
+	err := m.file.Close()
+	m.file = nil
+	return err
+}
+
+// Add stores seg unless the same pair is already known. It reports whether
+// the segment was new.
+func (m *Memory) Add(seg Segment) (bool, error) {
+	seg.Source = strings.TrimSpace(seg.Source)
+	seg.Target = strings.TrimSpace(seg.Target)
+	if seg.Source == "" || seg.Target == "" {
+		return false, nil
+	}
+	if seg.Created.IsZero() {
+		seg.Created = time.Now()
+	}
+	seg = canonicalLangs(seg)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.insert(seg) {
+		return false, nil
+	}
+	return true, m.write(record{Segment: seg})
+}
+
+// Delete removes seg from the memory. It reports whether the segment was
+// known.
+func (m *Memory) Delete(seg Segment) (bool, error) {
+	seg = canonicalLangs(seg)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.remove(seg) {
+		return false, nil
+	}
+	return true, m.write(record{Segment: seg, Deleted: true})
+}
+
+func (m *Memory) write(rec record) error {
+	if m.file == nil {
+		return nil
+	}
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode segment: %w", err)
+	}
+
+	unlock, err := state.Lock(m.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := m.reopenIfReplaced(); err != nil {
+		return err
+	}
+	if _, err := m.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write segment: %w", err)
+	}
+	if err := m.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync memory: %w", err)
+	}
+	return nil
+}
+
+// reopenIfReplaced reopens memory.jsonl when another process has replaced
+// it, so that appends do not go to the unlinked old file.
+func (m *Memory) reopenIfReplaced() error {
+	current, err := m.file.Stat()
+	if err == nil {
+		if onDisk, err := os.Stat(m.path); err == nil && os.SameFile(current, onDisk) {
+			return nil
+		}
+	}
+
+	file, err := os.OpenFile(m.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open memory: %w", err)
+	}
+	m.file.Close()
+	m.file = file
+	return nil
+}
+
+// Search returns segments for the same language pair whose source is at least
+// as similar to text as the memory's threshold, best matches first. An empty
+// or "auto" sourceLang matches any source language, and any other matches its
+// regional variants.
+func (m *Memory) Search(text, sourceLang, targetLang string, limit int) []Match {
+	query := normalize(text)
+	if query == "" {
+		return nil
+	}
+	sourceLang, targetLang = languages.Canonical(sourceLang), languages.Canonical(targetLang)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var matches []Match
+	for _, seg := range m.segments {
+		if seg.TargetLang != targetLang {
+			continue
+		}
+		// Regional variants of the source language are close enough; a
+		// target variant such as zh-Hant is not.
+		if sourceLang != "" && sourceLang != "auto" && languages.Base(seg.SourceLang) != languages.Base(sourceLang) {
+			continue
+		}
+
+		candidate := normalize(seg.Source)
+		if !lengthsCompatible(query, candidate, m.threshold) {
+			continue
+		}
+
+		if score := Similarity(query, candidate); score >= m.threshold {
+			matches = append(matches, Match{Segment: seg, Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Created.After(matches[j].Created)
+	})
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+func (m *Memory) Segments() []Segment {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	segments := make([]Segment, len(m.segments))
+	copy(segments, m.segments)
+	return segments
+}
+
+func (m *Memory) Size() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.segments)
+}
+
+func (m *Memory) insert(seg Segment) bool {
+	id := segmentID(seg)
+	if _, exists := m.index[id]; exists {
+		return false
+	}
+
+	m.index[id] = len(m.segments)
+	m.segments = append(m.segments, seg)
+	return true
+}
+
+func (m *Memory) remove(seg Segment) bool {
+	id := segmentID(seg)
+	i, exists := m.index[id]
+	if !exists {
+		return false
+	}
+
+	delete(m.index, id)
+	m.segments = append(m.segments[:i], m.segments[i+1:]...)
+	for other, j := range m.index {
+		if j > i {
+			m.index[other] = j - 1
+		}
+	}
+	return true
+}
+
+func segmentID(seg Segment) string {
+	return strings.Join([]string{seg.SourceLang, seg.TargetLang, normalize(seg.Source), normalize(seg.Target)}, "\x00")
+}
+
+// canonicalLangs writes the segment's languages as canonical BCP-47 tags, so
+// that "pt_br" and "pt-BR" are the same pair.
+func canonicalLangs(seg Segment) Segment {
+	seg.SourceLang = languages.Canonical(seg.SourceLang)
+	seg.TargetLang = languages.Canonical(seg.TargetLang)
+	return seg
+}
+
+func normalize(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// lengthsCompatible rules out pairs whose length difference alone already
+// puts them below threshold.
+func lengthsCompatible(a, b string, threshold float64) bool {
+	la, lb := len([]rune(a)), len([]rune(b))
+	if la > lb {
+		la, lb = lb, la
+	}
+	if lb == 0 {
+		return true
+	}
+	return float64(la)/float64(lb) >= threshold
+}
+
+// Similarity returns 1 minus the Levenshtein distance between a and b divided
+// by the length of the longer string, computed over runes.
+func Similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,244 @@
+package memory
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"translatego/internal/languages"
+)
+
+const (
+	tmxDateFormat   = "20060102T150405Z"
+	tmxProviderProp = "x-provider"
+)
+
+type tmxDocument struct {
+	XMLName xml.Name  `xml:"tmx"`
+	Version string    `xml:"version,attr"`
+	Header  tmxHeader `xml:"header"`
+	Units   []tmxUnit `xml:"body>tu"`
+}
+
+type tmxHeader struct {
+	CreationTool        string `xml:"creationtool,attr"`
+	CreationToolVersion string `xml:"creationtoolversion,attr"`
+	SegType             string `xml:"segtype,attr"`
+	OTMF                string `xml:"o-tmf,attr"`
+	AdminLang           string `xml:"adminlang,attr"`
+	SrcLang             string `xml:"srclang,attr"`
+	DataType            string `xml:"datatype,attr"`
+	CreationDate        string `xml:"creationdate,attr,omitempty"`
+}
+
+type tmxUnit struct {
+	SrcLang      string       `xml:"srclang,attr,omitempty"`
+	CreationDate string       `xml:"creationdate,attr,omitempty"`
+	CreationID   string       `xml:"creationid,attr,omitempty"`
+	Props        []tmxProp    `xml:"prop"`
+	Variants     []tmxVariant `xml:"tuv"`
+}
+
+type tmxProp struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type tmxVariant struct {
+	Lang string `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Seg  tmxSeg `xml:"seg"`
+}
+
+// tmxSeg keeps the inner markup of <seg>; inline tags such as <bpt> or <ph>
+// are stripped to plain text on import.
+type tmxSeg struct {
+	Inner string `xml:",innerxml"`
+}
+
+// ReadTMX parses a TMX 1.4b document into segments. Every translation unit
+// yields one segment per target variant.
+func ReadTMX(r io.Reader) ([]Segment, error) {
+	var doc tmxDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse TMX: %w", err)
+	}
+
+	var segments []Segment
+	for _, unit := range doc.Units {
+		srcLang := unit.SrcLang
+		if srcLang == "" || srcLang == "*all*" {
+			srcLang = doc.Header.SrcLang
+		}
+
+		var source *tmxVariant
+		for i := range unit.Variants {
+			if sameLang(unit.Variants[i].Lang, srcLang) {
+				source = &unit.Variants[i]
+				break
+			}
+		}
+		if source == nil && len(unit.Variants) > 0 {
+			source = &unit.Variants[0]
+		}
+		if source == nil {
+			continue
+		}
+
+		provider := unit.CreationID
+		for _, prop := range unit.Props {
+			if prop.Type == tmxProviderProp {
+				provider = strings.TrimSpace(prop.Value)
+			}
+		}
+
+		var created time.Time
+		if unit.CreationDate != "" {
+			created, _ = time.Parse(tmxDateFormat, unit.CreationDate)
+		}
+
+		sourceText, err := plainText(source.Seg.Inner)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range unit.Variants {
+			variant := &unit.Variants[i]
+			if variant == source {
+				continue
+			}
+			targetText, err := plainText(variant.Seg.Inner)
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, Segment{
+				Source:     sourceText,
+				Target:     targetText,
+				SourceLang: languages.Canonical(source.Lang),
+				TargetLang: languages.Canonical(variant.Lang),
+				Provider:   provider,
+				Created:    created,
+			})
+		}
+	}
+
+	return segments, nil
+}
+
+// WriteTMX writes segments as a TMX 1.4b document and returns how many it
+// wrote. Segments whose source language was never detected ("auto") have no
+// valid xml:lang and are left out.
+func WriteTMX(w io.Writer, segments []Segment, toolVersion string) (int, error) {
+	exportable := segments[:0:0]
+	for _, seg := range segments {
+		if knownLang(seg.SourceLang) && knownLang(seg.TargetLang) {
+			exportable = append(exportable, seg)
+		}
+	}
+	segments = exportable
+
+	srcLang := "*all*"
+	if len(segments) > 0 {
+		srcLang = segments[0].SourceLang
+		for _, seg := range segments {
+			if seg.SourceLang != srcLang {
+				srcLang = "*all*"
+				break
+			}
+		}
+	}
+
+	doc := tmxDocument{
+		Version: "1.4",
+		Header: tmxHeader{
+			CreationTool:        "translatego",
+			CreationToolVersion: toolVersion,
+			SegType:             "sentence",
+			OTMF:                "translatego",
+			AdminLang:           "en",
+			SrcLang:             srcLang,
+			DataType:            "plaintext",
+			CreationDate:        time.Now().UTC().Format(tmxDateFormat),
+		},
+	}
+
+	for _, seg := range segments {
+		unit := tmxUnit{
+			SrcLang:    seg.SourceLang,
+			CreationID: seg.Provider,
+			Variants: []tmxVariant{
+				{Lang: seg.SourceLang, Seg: tmxSeg{Inner: escape(seg.Source)}},
+				{Lang: seg.TargetLang, Seg: tmxSeg{Inner: escape(seg.Target)}},
+			},
+		}
+		if !seg.Created.IsZero() {
+			unit.CreationDate = seg.Created.UTC().Format(tmxDateFormat)
+		}
+		if seg.Provider != "" {
+			unit.Props = []tmxProp{{Type: tmxProviderProp, Value: seg.Provider}}
+		}
+		doc.Units = append(doc.Units, unit)
+	}
+
+	if _, err := io.WriteString(w, xml.Header+`<!DOCTYPE tmx SYSTEM "tmx14.dtd">`+"\n"); err != nil {
+		return 0, err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return 0, fmt.Errorf("failed to write TMX: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return len(segments), err
+}
+
+// plainText drops inline TMX markup from a <seg> body and unescapes the
+// remaining character data.
+func plainText(inner string) (string, error) {
+	dec := xml.NewDecoder(strings.NewReader("<seg>" + inner + "</seg>"))
+	var b strings.Builder
+	skip := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to parse TMX segment: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			// Native codes inside <bpt>, <ept>, <ph> and <it> are not text.
+			switch t.Name.Local {
+			case "bpt", "ept", "ph", "it":
+				skip++
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "bpt", "ept", "ph", "it":
+				skip--
+			}
+		case xml.CharData:
+			if skip == 0 {
+				b.Write(t)
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+func escape(text string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+func knownLang(lang string) bool {
+	return lang != "" && !strings.EqualFold(lang, "auto")
+}
+
+func sameLang(a, b string) bool {
+	return a != "" && b != "" && languages.Base(a) == languages.Base(b)
+}
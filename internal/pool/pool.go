@@ -0,0 +1,135 @@
+package pool
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Priority orders waiting requests; lower values are served first.
+type Priority int
+
+const (
+	PriorityInteractive Priority = iota
+	PriorityBatch
+)
+
+const (
+	DefaultGlobal      = 8
+	DefaultPerProvider = 2
+)
+
+// Pool bounds how many provider requests run at once, overall and per
+// provider. Requests that have to wait are admitted by priority, then in
+// arrival order.
+type Pool struct {
+	global      int
+	perProvider int
+	running     int
+	byProvider  map[string]int
+	waiting     []*waiter
+	seq         uint64
+	mu          sync.Mutex
+}
+
+type waiter struct {
+	provider string
+	priority Priority
+	seq      uint64
+	ready    chan struct{}
+}
+
+// New returns a pool; zero or negative limits mean unbounded.
+func New(global, perProvider int) *Pool {
+	return &Pool{
+		global:      global,
+		perProvider: perProvider,
+		byProvider:  make(map[string]int),
+	}
+}
+
+// Acquire blocks until a slot for provider is free and returns the function
+// that gives it back.
+func (p *Pool) Acquire(ctx context.Context, provider string, priority Priority) (func(), error) {
+	p.mu.Lock()
+	p.seq++
+	w := &waiter{provider: provider, priority: priority, seq: p.seq, ready: make(chan struct{})}
+	p.waiting = append(p.waiting, w)
+	sort.SliceStable(p.waiting, func(i, j int) bool {
+		if p.waiting[i].priority != p.waiting[j].priority {
+			return p.waiting[i].priority < p.waiting[j].priority
+		}
+		return p.waiting[i].seq < p.waiting[j].seq
+	})
+	p.dispatch()
+	p.mu.Unlock()
+
+	release := func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.running--
+		p.byProvider[provider]--
+		p.dispatch()
+	}
+
+	select {
+	case <-w.ready:
+		return release, nil
+	case <-ctx.Done():
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		select {
+		case <-w.ready:
+			// Granted while cancelling; hand the slot on.
+			p.running--
+			p.byProvider[provider]--
+			p.dispatch()
+		default:
+			p.remove(w)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// Stats returns the number of running and waiting requests.
+func (p *Pool) Stats() (running, waiting int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.running, len(p.waiting)
+}
+
+// dispatch admits waiters in order while there is room. A waiter whose
+// provider is busy does not hold up waiters for other providers.
+func (p *Pool) dispatch() {
+	remaining := p.waiting[:0]
+	for _, w := range p.waiting {
+		if p.fits(w.provider) {
+			p.running++
+			p.byProvider[w.provider]++
+			close(w.ready)
+			continue
+		}
+		remaining = append(remaining, w)
+	}
+	for i := len(remaining); i < len(p.waiting); i++ {
+		p.waiting[i] = nil
+	}
+	p.waiting = remaining
+}
+
+func (p *Pool) fits(provider string) bool {
+	if p.global > 0 && p.running >= p.global {
+		return false
+	}
+	return p.perProvider <= 0 || p.byProvider[provider] < p.perProvider
+}
+
+func (p *Pool) remove(w *waiter) {
+	for i, candidate := range p.waiting {
+		if candidate == w {
+			p.waiting = append(p.waiting[:i], p.waiting[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+type arrival struct {
+	name     string
+	provider string
+	priority Priority
+}
+
+func TestAdmissionOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		arrivals []arrival
+		want     []string
+	}{
+		{
+			name: "arrival order within a priority",
+			arrivals: []arrival{
+				{"a", "GOOGLE", PriorityInteractive},
+				{"b", "GOOGLE", PriorityInteractive},
+				{"c", "GOOGLE", PriorityInteractive},
+			},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name: "interactive before batch",
+			arrivals: []arrival{
+				{"batch1", "GOOGLE", PriorityBatch},
+				{"batch2", "GOOGLE", PriorityBatch},
+				{"user1", "GOOGLE", PriorityInteractive},
+				{"batch3", "GOOGLE", PriorityBatch},
+				{"user2", "GOOGLE", PriorityInteractive},
+			},
+			want: []string{"user1", "user2", "batch1", "batch2", "batch3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(1, 0)
+			hold, err := p.Acquire(context.Background(), "GOOGLE", PriorityInteractive)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			admitted := make(chan string, len(tt.arrivals))
+			for i, a := range tt.arrivals {
+				go func() {
+					release, err := p.Acquire(context.Background(), a.provider, a.priority)
+					if err != nil {
+						admitted <- err.Error()
+						return
+					}
+					admitted <- a.name
+					release()
+				}()
+				waitFor(t, p, 1, i+1)
+			}
+
+			hold()
+			var got []string
+			for range tt.arrivals {
+				got = append(got, <-admitted)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("admitted %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBusyProviderDoesNotHoldUpOthers(t *testing.T) {
+	p := New(2, 1)
+	hold, _ := p.Acquire(context.Background(), "GOOGLE", PriorityInteractive)
+
+	admitted := make(chan func(), 1)
+	go func() {
+		release, _ := p.Acquire(context.Background(), "GOOGLE", PriorityInteractive)
+		admitted <- release
+	}()
+	waitFor(t, p, 1, 1)
+
+	release, err := p.Acquire(context.Background(), "DEEPL", PriorityBatch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if running, waiting := p.Stats(); running != 2 || waiting != 1 {
+		t.Errorf("running %d, waiting %d, want 2, 1", running, waiting)
+	}
+
+	release()
+	hold()
+	(<-admitted)()
+}
+
+func TestCancelledWaiterHandsOn(t *testing.T) {
+	p := New(1, 0)
+	hold, _ := p.Acquire(context.Background(), "GOOGLE", PriorityInteractive)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := p.Acquire(ctx, "GOOGLE", PriorityInteractive)
+		cancelled <- err
+	}()
+	waitFor(t, p, 1, 1)
+
+	admitted := make(chan func(), 1)
+	go func() {
+		release, _ := p.Acquire(context.Background(), "GOOGLE", PriorityBatch)
+		admitted <- release
+	}()
+	waitFor(t, p, 1, 2)
+
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled Acquire = %v, want context.Canceled", err)
+	}
+	waitFor(t, p, 1, 1)
+
+	hold()
+	release := <-admitted
+	if running, waiting := p.Stats(); running != 1 || waiting != 0 {
+		t.Errorf("after handoff: running %d, waiting %d, want 1, 0", running, waiting)
+	}
+	release()
+	if running, _ := p.Stats(); running != 0 {
+		t.Errorf("after release: running %d, want 0", running)
+	}
+}
+
+// A waiter granted a slot while its context is being cancelled must give the
+// slot back rather than leak it.
+func TestCancelWhileGrantedDoesNotLeak(t *testing.T) {
+	p := New(1, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for i := 0; i < 100; i++ {
+		release, err := p.Acquire(ctx, "GOOGLE", PriorityInteractive)
+		if err == nil {
+			release()
+		}
+		if running, waiting := p.Stats(); running != 0 || waiting != 0 {
+			t.Fatalf("attempt %d: running %d, waiting %d, want 0, 0", i+1, running, waiting)
+		}
+	}
+}
+
+// waitFor blocks until the pool reports the given number of running and
+// waiting requests.
+func waitFor(t *testing.T, p *Pool, running, waiting int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		r, w := p.Stats()
+		if r == running && w == waiting {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pool has %d running and %d waiting, want %d and %d", r, w, running, waiting)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,154 @@
+// Package postprocess cleans up provider output before it is shown, cached
+// or compared with other providers.
+package postprocess
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+type Step string
+
+const (
+	// StepEntities decodes HTML entities such as &amp; and &#39;.
+	StepEntities Step = "entities"
+	// StepPlus turns '+' back into spaces, for providers that return
+	// form-encoded text.
+	StepPlus Step = "plus"
+	// StepPreamble drops lead-ins such as "Here is the translation:".
+	StepPreamble Step = "preamble"
+	// StepQuotes drops quotes wrapped around the whole translation when the
+	// input was not quoted.
+	StepQuotes Step = "quotes"
+	// StepWhitespace collapses runs of spaces and blank lines and trims the
+	// result.
+	StepWhitespace Step = "whitespace"
+	// StepNFC normalizes to Unicode NFC, so that equal text compares equal.
+	StepNFC Step = "nfc"
+)
+
+// Pipeline is a sequence of steps applied in order.
+type Pipeline []Step
+
+func Steps() []Step {
+	return []Step{StepEntities, StepPlus, StepPreamble, StepQuotes, StepWhitespace, StepNFC}
+}
+
+// DefaultPipeline returns the steps a provider's output needs: entity
+// decoding for the web services that return HTML-escaped text, preamble and
+// quote stripping for LLMs, and whitespace and NFC normalization for all.
+func DefaultPipeline(serviceName string) Pipeline {
+	switch serviceName {
+	case "GOOGLE", "MYMEMORY", "REVERSO", "REVERSO2":
+		return Pipeline{StepEntities, StepWhitespace, StepNFC}
+	case "OPENAI", "OPENROUTER":
+		return Pipeline{StepPreamble, StepQuotes, StepWhitespace, StepNFC}
+	default:
+		return Pipeline{StepWhitespace, StepNFC}
+	}
+}
+
+// Parse builds a pipeline from step names, as written in the config file.
+func Parse(names []string) (Pipeline, error) {
+	pipeline := make(Pipeline, 0, len(names))
+	for _, name := range names {
+		step := Step(strings.ToLower(strings.TrimSpace(name)))
+		found := false
+		for _, known := range Steps() {
+			if step == known {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown post-processing step %q", name)
+		}
+		pipeline = append(pipeline, step)
+	}
+	return pipeline, nil
+}
+
+// Apply runs the pipeline on a translation of input.
+func (p Pipeline) Apply(input, translation string) string {
+	for _, step := range p {
+		switch step {
+		case StepEntities:
+			translation = html.UnescapeString(translation)
+		case StepPlus:
+			translation = strings.ReplaceAll(translation, "+", " ")
+		case StepPreamble:
+			translation = stripPreamble(translation)
+		case StepQuotes:
+			translation = stripQuotes(input, translation)
+		case StepWhitespace:
+			translation = normalizeWhitespace(translation)
+		case StepNFC:
+			translation = norm.NFC.String(translation)
+		}
+	}
+	return translation
+}
+
+var (
+	// A first line that only introduces the translation.
+	preambleLine = regexp.MustCompile(`(?i)^(sure|certainly|of course)?[!,.]?\s*(here(’s|'s| is)\s+(the\s+|your\s+)?(\p{L}+\s+)?translation(\s+(of|in|into)\b.*)?|(the\s+)?(\p{L}+\s+)?translation(\s+(of|in|into)\b.*)?\s+is)\s*:\s*$`)
+	// A label in front of the translation on the same line.
+	preambleLabel = regexp.MustCompile(`(?i)^(translation|translated text)\s*:\s*`)
+)
+
+func stripPreamble(translation string) string {
+	translation = strings.TrimSpace(translation)
+	if first, rest, found := strings.Cut(translation, "\n"); found && preambleLine.MatchString(strings.TrimSpace(first)) {
+		translation = strings.TrimSpace(rest)
+	}
+	return preambleLabel.ReplaceAllString(translation, "")
+}
+
+var quotePairs = [][2]string{
+	{`"`, `"`}, {`'`, `'`}, {"“", "”"}, {"„", "“"}, {"«", "»"}, {"「", "」"}, {"『", "』"},
+}
+
+func stripQuotes(input, translation string) string {
+	input = strings.TrimSpace(input)
+	translation = strings.TrimSpace(translation)
+
+	for _, pair := range quotePairs {
+		if quoted(input, pair) {
+			return translation
+		}
+	}
+	for _, pair := range quotePairs {
+		if !quoted(translation, pair) {
+			continue
+		}
+		inner := translation[len(pair[0]) : len(translation)-len(pair[1])]
+		// "a" and "b" is not one quoted text.
+		if !strings.Contains(inner, pair[1]) {
+			return strings.TrimSpace(inner)
+		}
+	}
+	return translation
+}
+
+func quoted(s string, pair [2]string) bool {
+	return len(s) >= len(pair[0])+len(pair[1]) && strings.HasPrefix(s, pair[0]) && strings.HasSuffix(s, pair[1])
+}
+
+var (
+	spaceRun   = regexp.MustCompile(`[ \t]+`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+func normalizeWhitespace(translation string) string {
+	translation = strings.ReplaceAll(translation, "\r\n", "\n")
+	lines := strings.Split(translation, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(spaceRun.ReplaceAllString(line, " "))
+	}
+	translation = blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(translation)
+}
@@ -0,0 +1,306 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"translatego/internal/state"
+)
+
+const (
+	fileName      = "quota.json"
+	DefaultWarnAt = 0.8
+	dayFormat     = "2006-01-02"
+	monthFormat   = "2006-01"
+)
+
+// Limits caps the characters and tokens a provider may use per calendar day
+// and month. Zero means no cap. WarnAt is the used fraction at which a warning
+// is raised.
+type Limits struct {
+	DailyCharacters   int64
+	MonthlyCharacters int64
+	DailyTokens       int64
+	MonthlyTokens     int64
+	WarnAt            float64
+}
+
+func (l Limits) IsZero() bool {
+	return l.DailyCharacters == 0 && l.MonthlyCharacters == 0 &&
+		l.DailyTokens == 0 && l.MonthlyTokens == 0
+}
+
+type Usage struct {
+	Characters int64 `json:"characters"`
+	Tokens     int64 `json:"tokens,omitempty"`
+}
+
+type providerState struct {
+	Day     string `json:"day"`
+	Month   string `json:"month"`
+	Daily   Usage  `json:"daily"`
+	Monthly Usage  `json:"monthly"`
+}
+
+// Status is the usage of one provider against its limits.
+type Status struct {
+	Provider string
+	Limits   Limits
+	Daily    Usage
+	Monthly  Usage
+}
+
+// Tracker counts usage per provider and persists it, so that quotas hold
+// across runs.
+type Tracker struct {
+	path   string
+	limits map[string]Limits
+	state  map[string]*providerState
+	mu     sync.Mutex
+}
+
+// DefaultLimits returns the free tier quotas of the providers that publish
+// one.
+func DefaultLimits(serviceName string) Limits {
+	switch serviceName {
+	case "MYMEMORY":
+		return Limits{DailyCharacters: 5000, WarnAt: DefaultWarnAt}
+	case "DEEPL":
+		return Limits{MonthlyCharacters: 500000, WarnAt: DefaultWarnAt}
+	default:
+		return Limits{WarnAt: DefaultWarnAt}
+	}
+}
+
+// New returns a tracker that keeps usage in memory only. Providers missing
+// from limits use DefaultLimits.
+func New(limits map[string]Limits) *Tracker {
+	if limits == nil {
+		limits = make(map[string]Limits)
+	}
+	return &Tracker{
+		limits: limits,
+		state:  make(map[string]*providerState),
+	}
+}
+
+// Open loads the usage stored in dir and saves every recorded use to it.
+func Open(dir string, limits map[string]Limits) (*Tracker, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	t := New(limits)
+	t.path = filepath.Join(dir, fileName)
+	// A corrupt file is replaced by the next Record.
+	loaded := make(map[string]*providerState)
+	err := state.Load(t.path, &loaded)
+	if err != nil && !errors.Is(err, state.ErrCorrupt) {
+		return nil, err
+	}
+	if err == nil {
+		t.state = loaded
+	}
+	return t, nil
+}
+
+// Record adds the characters and tokens of one request to the provider's
+// usage. It returns the new status and whether this request crossed the warn
+// threshold. The usage is counted even when the error is set, such as when a
+// corrupt state file had to be reset.
+func (t *Tracker) Record(provider string, characters, tokens int) (Status, bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var after Status
+	var crossed bool
+	err := t.update(func() {
+		before := t.status(provider, time.Now())
+		current := t.current(provider, time.Now())
+		current.Daily.Characters += int64(characters)
+		current.Daily.Tokens += int64(tokens)
+		current.Monthly.Characters += int64(characters)
+		current.Monthly.Tokens += int64(tokens)
+		after = t.status(provider, time.Now())
+
+		warnAt := after.Limits.WarnAt
+		crossed = warnAt > 0 && before.Used() < warnAt && after.Used() >= warnAt
+	})
+
+	return after, crossed, err
+}
+
+func (t *Tracker) Status(provider string) Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.view()
+	return t.status(provider, time.Now())
+}
+
+// Exhausted reports whether the provider has used up any of its quotas.
+func (t *Tracker) Exhausted(provider string) bool {
+	return t.Status(provider).Exhausted()
+}
+
+func (t *Tracker) status(provider string, now time.Time) Status {
+	status := Status{Provider: provider, Limits: t.limitsFor(provider)}
+	if usage, exists := t.state[provider]; exists {
+		if usage.Day == now.Format(dayFormat) {
+			status.Daily = usage.Daily
+		}
+		if usage.Month == now.Format(monthFormat) {
+			status.Monthly = usage.Monthly
+		}
+	}
+	return status
+}
+
+// current returns the provider's state, starting a new day or month when the
+// stored one has passed.
+func (t *Tracker) current(provider string, now time.Time) *providerState {
+	usage, exists := t.state[provider]
+	if !exists {
+		usage = &providerState{}
+		t.state[provider] = usage
+	}
+
+	if day := now.Format(dayFormat); usage.Day != day {
+		usage.Day = day
+		usage.Daily = Usage{}
+	}
+	if month := now.Format(monthFormat); usage.Month != month {
+		usage.Month = month
+		usage.Monthly = Usage{}
+	}
+	return usage
+}
+
+func (t *Tracker) limitsFor(provider string) Limits {
+	if limits, exists := t.limits[provider]; exists {
+		return limits
+	}
+	return DefaultLimits(provider)
+}
+
+// update runs fn against the usage in the state file, holding its lock so that
+// no other process records in between, and against the in-process usage when
+// there is no file or it is unusable.
+func (t *Tracker) update(fn func()) error {
+	if t.path == "" {
+		fn()
+		return nil
+	}
+
+	loaded := make(map[string]*providerState)
+	ran, err := state.Update(t.path, &loaded, func() {
+		t.state = loaded
+		fn()
+	})
+	if !ran {
+		fn()
+	}
+	if err != nil {
+		return fmt.Errorf("quota state: %w", err)
+	}
+	return nil
+}
+
+// view refreshes the in-process usage from the state file, keeping it as is
+// when the file cannot be read.
+func (t *Tracker) view() {
+	if t.path == "" {
+		return
+	}
+
+	loaded := make(map[string]*providerState)
+	if err := state.Load(t.path, &loaded); err == nil {
+		t.state = loaded
+	}
+}
+
+// Used returns the largest used fraction over all capped quotas, or -1 when
+// the provider has none.
+func (s Status) Used() float64 {
+	pair, ok := s.mostUsed()
+	if !ok {
+		return -1
+	}
+	return pair.fraction()
+}
+
+func (s Status) Exhausted() bool {
+	return s.Used() >= 1
+}
+
+// Summary describes the most used quota, such as "4,200/5,000 characters
+// today", or is empty when the provider has no quota.
+func (s Status) Summary() string {
+	pair, ok := s.mostUsed()
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s %s %s", formatCount(pair.used), formatCount(pair.limit), pair.unit, pair.period)
+}
+
+// Remaining describes what is left of the most used quota, such as "800
+// characters left today".
+func (s Status) Remaining() string {
+	pair, ok := s.mostUsed()
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s %s left %s", formatCount(max(pair.limit-pair.used, 0)), pair.unit, pair.period)
+}
+
+type quotaPair struct {
+	used   int64
+	limit  int64
+	unit   string
+	period string
+}
+
+func (p quotaPair) fraction() float64 {
+	return float64(p.used) / float64(p.limit)
+}
+
+func (s Status) mostUsed() (quotaPair, bool) {
+	var best quotaPair
+	found := false
+	for _, pair := range s.pairs() {
+		if pair.limit > 0 && (!found || pair.fraction() > best.fraction()) {
+			best = pair
+			found = true
+		}
+	}
+	return best, found
+}
+
+func (s Status) pairs() []quotaPair {
+	return []quotaPair{
+		{s.Daily.Characters, s.Limits.DailyCharacters, "characters", "today"},
+		{s.Monthly.Characters, s.Limits.MonthlyCharacters, "characters", "this month"},
+		{s.Daily.Tokens, s.Limits.DailyTokens, "tokens", "today"},
+		{s.Monthly.Tokens, s.Limits.MonthlyTokens, "tokens", "this month"},
+	}
+}
+
+func formatCount(n int64) string {
+	digits := fmt.Sprint(n)
+	if len(digits) <= 3 {
+		return digits
+	}
+
+	var out []byte
+	for i, digit := range []byte(digits) {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			out = append(out, ',')
+		}
+		out = append(out, digit)
+	}
+	return string(out)
+}
@@ -0,0 +1,65 @@
+package quota
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"translatego/internal/state"
+)
+
+// Trackers sharing a state file must see each other's usage, however close
+// together they record.
+func TestRecordSharesUsage(t *testing.T) {
+	dir := t.TempDir()
+	limits := map[string]Limits{"DEEPL": {DailyCharacters: 100}}
+
+	first, err := Open(dir, limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Open(dir, limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		tracker := first
+		if i%2 == 1 {
+			tracker = second
+		}
+		if _, _, err := tracker.Record("DEEPL", 10, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tracker := range []*Tracker{first, second} {
+		if status := tracker.Status("DEEPL"); status.Daily.Characters != 100 || !status.Exhausted() {
+			t.Errorf("daily usage = %d, exhausted %v, want 100, true", status.Daily.Characters, status.Exhausted())
+		}
+	}
+}
+
+func TestRecordResetsCorruptState(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, fileName), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tracker, err := Open(dir, map[string]Limits{"DEEPL": {DailyCharacters: 10}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := tracker.Record("DEEPL", 4, 0); !errors.Is(err, state.ErrCorrupt) {
+		t.Fatalf("first Record error = %v, want ErrCorrupt", err)
+	}
+	status, _, err := tracker.Record("DEEPL", 6, 0)
+	if err != nil {
+		t.Fatalf("second Record error = %v, want none", err)
+	}
+	if status.Daily.Characters != 10 || !status.Exhausted() {
+		t.Errorf("daily usage = %d, exhausted %v, want 10, true", status.Daily.Characters, status.Exhausted())
+	}
+}
@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Feedback is what a provider reported about its limits in a response.
+// Remaining counts are -1 when the provider did not send them.
+type Feedback struct {
+	RetryAfter      time.Duration
+	Remaining       int
+	Reset           time.Duration
+	RemainingTokens int
+	TokensReset     time.Duration
+}
+
+func (f Feedback) IsZero() bool {
+	return f.RetryAfter == 0 && f.Remaining < 0 && f.RemainingTokens < 0
+}
+
+// ParseHeaders reads Retry-After, the common X-RateLimit-Remaining/Reset
+// headers and OpenAI's x-ratelimit-*-requests/-tokens headers.
+func ParseHeaders(h http.Header, now time.Time) Feedback {
+	feedback := Feedback{Remaining: -1, RemainingTokens: -1}
+
+	if ms, err := strconv.ParseFloat(h.Get("Retry-After-Ms"), 64); err == nil && ms > 0 {
+		feedback.RetryAfter = time.Duration(ms * float64(time.Millisecond))
+	} else if value := h.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+			feedback.RetryAfter = time.Duration(seconds * float64(time.Second))
+		} else if at, err := http.ParseTime(value); err == nil {
+			feedback.RetryAfter = at.Sub(now)
+		}
+		feedback.RetryAfter = max(feedback.RetryAfter, 0)
+	}
+
+	if remaining, ok := parseCount(h, "X-Ratelimit-Remaining-Requests", "X-Ratelimit-Remaining", "Ratelimit-Remaining"); ok {
+		feedback.Remaining = remaining
+	}
+	feedback.Reset = parseReset(h, now, "X-Ratelimit-Reset-Requests", "X-Ratelimit-Reset", "Ratelimit-Reset")
+
+	if remaining, ok := parseCount(h, "X-Ratelimit-Remaining-Tokens"); ok {
+		feedback.RemainingTokens = remaining
+	}
+	feedback.TokensReset = parseReset(h, now, "X-Ratelimit-Reset-Tokens")
+
+	return feedback
+}
+
+func parseCount(h http.Header, names ...string) (int, bool) {
+	for _, name := range names {
+		if value := h.Get(name); value != "" {
+			if count, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+				return count, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// parseReset accepts a duration ("6m0s", "20ms", as sent by OpenAI), a number
+// of seconds, or a Unix timestamp in seconds or milliseconds.
+func parseReset(h http.Header, now time.Time, names ...string) time.Duration {
+	for _, name := range names {
+		value := strings.TrimSpace(h.Get(name))
+		if value == "" {
+			continue
+		}
+
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			switch {
+			case number > 1e12:
+				return max(time.UnixMilli(int64(number)).Sub(now), 0)
+			case number > 1e9:
+				return max(time.Unix(int64(number), 0).Sub(now), 0)
+			default:
+				return time.Duration(number * float64(time.Second))
+			}
+		}
+		if reset, err := time.ParseDuration(value); err == nil {
+			return reset
+		}
+	}
+	return 0
+}
@@ -1,82 +1,213 @@
 package ratelimit
 
 import (
+	"math"
 	"sync"
 	"time"
 )
 
+// Limits describes a token bucket: Requests and Characters refill evenly over
+// Interval, and up to Burst requests may be made at once. Zero Requests or
+// Characters mean that dimension is unlimited.
+type Limits struct {
+	Requests   int
+	Characters int
+	Interval   time.Duration
+	Burst      int
+}
+
 type Manager struct {
 	limiters map[string]*Limiter
-	mu       sync.RWMutex
+	limits   map[string]Limits
+	path     string
+	mu       sync.Mutex
 }
 
 type Limiter struct {
-	Requests    int
-	LastReset   time.Time
-	MaxRequests int
-	Window      time.Duration
+	limits      Limits
+	requests    float64
+	characters  float64
+	updated     time.Time
+	pausedUntil time.Time
+}
+
+// DefaultLimits returns the built-in limits for a provider, tuned to stay
+// below what the public endpoints tolerate.
+func DefaultLimits(serviceName string) Limits {
+	switch serviceName {
+	case "GOOGLE":
+		return Limits{Requests: 60, Interval: time.Minute, Burst: 10}
+	case "DEEPL":
+		return Limits{Requests: 30, Characters: 50000, Interval: time.Minute, Burst: 5}
+	case "REVERSO", "REVERSO2":
+		return Limits{Requests: 20, Interval: time.Minute, Burst: 3}
+	case "MYMEMORY":
+		return Limits{Requests: 30, Characters: 10000, Interval: time.Minute, Burst: 5}
+	case "LINGVA":
+		return Limits{Requests: 30, Interval: time.Minute, Burst: 5}
+	case "OPENAI":
+		return Limits{Requests: 60, Characters: 120000, Interval: time.Minute, Burst: 10}
+	case "OPENROUTER":
+		return Limits{Requests: 20, Characters: 60000, Interval: time.Minute, Burst: 5}
+	default:
+		return Limits{Requests: 30, Interval: time.Minute, Burst: 5}
+	}
 }
 
 func NewManager() *Manager {
 	return &Manager{
 		limiters: make(map[string]*Limiter),
+		limits:   make(map[string]Limits),
 	}
 }
 
-func (m *Manager) GetLimiter(serviceName string, maxRequests int, window time.Duration) *Limiter {
+// SetLimits replaces the limits of a provider, overriding DefaultLimits.
+func (m *Manager) SetLimits(serviceName string, limits Limits) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if limiter, exists := m.limiters[serviceName]; exists {
-		return limiter
-	}
+	m.limits[serviceName] = limits
+	delete(m.limiters, serviceName)
+}
 
-	limiter := &Limiter{
-		Requests:    0,
-		LastReset:   time.Now(),
-		MaxRequests: maxRequests,
-		Window:      window,
-	}
-	m.limiters[serviceName] = limiter
-	return limiter
+func (m *Manager) Limits(serviceName string) Limits {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.limitsFor(serviceName)
+}
+
+// Allow takes one request and chars characters from the provider's bucket and
+// reports whether there were enough tokens.
+func (m *Manager) Allow(serviceName string, chars int) bool {
+	return m.Reserve(serviceName, chars) == 0
 }
 
-func (m *Manager) Allow(serviceName string) bool {
+// Reserve takes one request and chars characters from the provider's bucket
+// and returns 0, or leaves the bucket untouched and returns how long to wait
+// until the tokens are available.
+func (m *Manager) Reserve(serviceName string, chars int) time.Duration {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	var wait time.Duration
+	m.shared(func() {
+		wait = m.limiter(serviceName).reserve(chars, time.Now())
+	})
+	return wait
+}
+
+// Observe applies what the provider reported about its limits, so that later
+// reservations wait as long as the server asked.
+func (m *Manager) Observe(serviceName string, feedback Feedback) {
+	if feedback.IsZero() {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.shared(func() {
+		m.limiter(serviceName).observe(feedback, time.Now())
+	})
+}
+
+func (m *Manager) limiter(serviceName string) *Limiter {
 	limiter, exists := m.limiters[serviceName]
 	if !exists {
-		limiter = &Limiter{
-			Requests:    0,
-			LastReset:   time.Now(),
-			MaxRequests: 10,
-			Window:      time.Minute,
-		}
+		limiter = newLimiter(m.limitsFor(serviceName), time.Now())
 		m.limiters[serviceName] = limiter
 	}
+	return limiter
+}
+
+func (m *Manager) limitsFor(serviceName string) Limits {
+	if limits, exists := m.limits[serviceName]; exists {
+		return limits
+	}
+	return DefaultLimits(serviceName)
+}
 
-	return limiter.Allow()
+func newLimiter(limits Limits, now time.Time) *Limiter {
+	if limits.Interval <= 0 {
+		limits.Interval = time.Minute
+	}
+	if limits.Burst <= 0 || limits.Burst > limits.Requests {
+		limits.Burst = limits.Requests
+	}
+
+	return &Limiter{
+		limits:     limits,
+		requests:   float64(limits.Burst),
+		characters: float64(limits.Characters),
+		updated:    now,
+	}
 }
 
-func (m *Manager) RecordRequest(serviceName string) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+func (l *Limiter) refill(now time.Time) {
+	elapsed := now.Sub(l.updated)
+	if elapsed <= 0 {
+		return
+	}
+	l.updated = now
+
+	share := float64(elapsed) / float64(l.limits.Interval)
+	l.requests = math.Min(l.requests+share*float64(l.limits.Requests), float64(l.limits.Burst))
+	l.characters = math.Min(l.characters+share*float64(l.limits.Characters), float64(l.limits.Characters))
+}
+
+func (l *Limiter) observe(feedback Feedback, now time.Time) {
+	l.refill(now)
 
-	if limiter, exists := m.limiters[serviceName]; exists {
-		limiter.RecordRequest()
+	pause := feedback.RetryAfter
+	if feedback.Remaining == 0 {
+		pause = max(pause, feedback.Reset)
+	}
+	if feedback.RemainingTokens == 0 {
+		pause = max(pause, feedback.TokensReset)
+	}
+	if until := now.Add(pause); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+
+	if feedback.Remaining > 0 && l.limits.Requests > 0 {
+		l.requests = math.Min(l.requests, float64(feedback.Remaining))
 	}
 }
 
-func (l *Limiter) Allow() bool {
-	now := time.Now()
-	if now.Sub(l.LastReset) > l.Window {
-		l.Requests = 0
-		l.LastReset = now
+func (l *Limiter) reserve(chars int, now time.Time) time.Duration {
+	l.refill(now)
+
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+
+	// A text longer than the whole character budget waits for a full bucket
+	// instead of never going through.
+	needChars := math.Min(float64(chars), float64(l.limits.Characters))
+
+	var wait time.Duration
+	if l.limits.Requests > 0 && l.requests < 1 {
+		wait = max(wait, l.timeFor(1-l.requests, l.limits.Requests))
+	}
+	if l.limits.Characters > 0 && l.characters < needChars {
+		wait = max(wait, l.timeFor(needChars-l.characters, l.limits.Characters))
+	}
+	if wait > 0 {
+		return wait
+	}
+
+	if l.limits.Requests > 0 {
+		l.requests--
+	}
+	if l.limits.Characters > 0 {
+		l.characters -= needChars
 	}
-	return l.Requests < l.MaxRequests
+	return 0
 }
 
-func (l *Limiter) RecordRequest() {
-	l.Requests++
+// timeFor returns how long it takes to refill missing tokens at perInterval
+// tokens per Interval.
+func (l *Limiter) timeFor(missing float64, perInterval int) time.Duration {
+	wait := time.Duration(missing / float64(perInterval) * float64(l.limits.Interval))
+	return max(wait, time.Millisecond)
 }
@@ -0,0 +1,189 @@
+package ratelimit
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRefill(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		limits Limits
+		used   int // reservations of chars made at start
+		chars  int
+		after  time.Duration
+		want   time.Duration
+	}{
+		{
+			name:   "burst goes through at once",
+			limits: Limits{Requests: 60, Interval: time.Minute, Burst: 3},
+			used:   2,
+			want:   0,
+		},
+		{
+			name:   "empty bucket waits for one token",
+			limits: Limits{Requests: 60, Interval: time.Minute, Burst: 3},
+			used:   3,
+			want:   time.Second,
+		},
+		{
+			name:   "partial refill shortens the wait",
+			limits: Limits{Requests: 60, Interval: time.Minute, Burst: 3},
+			used:   3,
+			after:  400 * time.Millisecond,
+			want:   600 * time.Millisecond,
+		},
+		{
+			name:   "refilled token goes through",
+			limits: Limits{Requests: 60, Interval: time.Minute, Burst: 3},
+			used:   3,
+			after:  time.Second,
+			want:   0,
+		},
+		{
+			name:   "refill stops at burst",
+			limits: Limits{Requests: 60, Interval: time.Minute, Burst: 3},
+			used:   3,
+			after:  time.Hour,
+			want:   0,
+		},
+		{
+			name:   "characters refill over the interval",
+			limits: Limits{Characters: 1000, Interval: time.Minute},
+			used:   1,
+			chars:  1000,
+			after:  30 * time.Second,
+			want:   30 * time.Second,
+		},
+		{
+			name:   "text longer than the budget waits for a full bucket",
+			limits: Limits{Characters: 1000, Interval: time.Minute},
+			used:   1,
+			chars:  5000,
+			want:   time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := newLimiter(tt.limits, start)
+			for i := 0; i < tt.used; i++ {
+				if wait := limiter.reserve(tt.chars, start); wait != 0 {
+					t.Fatalf("reservation %d waits %v, want none", i+1, wait)
+				}
+			}
+
+			if got := limiter.reserve(tt.chars, start.Add(tt.after)); got != tt.want {
+				t.Errorf("wait = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// A reservation that has to wait must not take tokens, or waiting callers
+// would push each other further back.
+func TestReserveLeavesBucketWhenWaiting(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newLimiter(Limits{Requests: 60, Interval: time.Minute, Burst: 1}, start)
+
+	limiter.reserve(0, start)
+	for i := 0; i < 3; i++ {
+		if wait := limiter.reserve(0, start); wait != time.Second {
+			t.Fatalf("waiting reservation %d = %v, want 1s", i+1, wait)
+		}
+	}
+	if wait := limiter.reserve(0, start.Add(time.Second)); wait != 0 {
+		t.Errorf("reservation after 1s waits %v, want none", wait)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    time.Duration
+	}{
+		{"seconds", map[string]string{"Retry-After": "30"}, 30 * time.Second},
+		{"fractional seconds", map[string]string{"Retry-After": "1.5"}, 1500 * time.Millisecond},
+		{"HTTP date", map[string]string{"Retry-After": "Thu, 01 Jan 2026 12:02:00 GMT"}, 2 * time.Minute},
+		{"date in the past", map[string]string{"Retry-After": "Thu, 01 Jan 2026 11:00:00 GMT"}, 0},
+		{"milliseconds win", map[string]string{"Retry-After-Ms": "250", "Retry-After": "30"}, 250 * time.Millisecond},
+		{"garbage", map[string]string{"Retry-After": "soon"}, 0},
+		{"missing", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := make(http.Header)
+			for name, value := range tt.headers {
+				h.Set(name, value)
+			}
+			if got := ParseHeaders(h, now).RetryAfter; got != tt.want {
+				t.Errorf("RetryAfter = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObservePausesReservations(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	limits := Limits{Requests: 60, Interval: time.Minute, Burst: 10}
+
+	tests := []struct {
+		name     string
+		feedback Feedback
+		after    time.Duration
+		want     time.Duration
+	}{
+		{
+			name:     "Retry-After pauses the bucket",
+			feedback: Feedback{RetryAfter: 20 * time.Second, Remaining: -1, RemainingTokens: -1},
+			after:    5 * time.Second,
+			want:     15 * time.Second,
+		},
+		{
+			name:     "pause ends after Retry-After",
+			feedback: Feedback{RetryAfter: 20 * time.Second, Remaining: -1, RemainingTokens: -1},
+			after:    20 * time.Second,
+			want:     0,
+		},
+		{
+			name:     "no requests left waits for the reset",
+			feedback: Feedback{Remaining: 0, Reset: time.Minute, RemainingTokens: -1},
+			want:     time.Minute,
+		},
+		{
+			name:     "the longer of Retry-After and reset wins",
+			feedback: Feedback{RetryAfter: 90 * time.Second, Remaining: 0, Reset: time.Minute, RemainingTokens: -1},
+			want:     90 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := newLimiter(limits, start)
+			limiter.observe(tt.feedback, start)
+			if got := limiter.reserve(0, start.Add(tt.after)); got != tt.want {
+				t.Errorf("wait = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObserveRemainingCapsBucket(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newLimiter(Limits{Requests: 60, Interval: time.Minute, Burst: 10}, start)
+
+	limiter.observe(Feedback{Remaining: 1, RemainingTokens: -1}, start)
+	if wait := limiter.reserve(0, start); wait != 0 {
+		t.Fatalf("first reservation waits %v, want none", wait)
+	}
+	if wait := limiter.reserve(0, start); wait != time.Second {
+		t.Errorf("second reservation waits %v, want 1s", wait)
+	}
+}
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"translatego/internal/state"
+)
+
+const stateFileName = "ratelimit.json"
+
+// bucketState is the part of a Limiter that is shared between processes.
+type bucketState struct {
+	Requests    float64 `json:"requests"`
+	Characters  float64 `json:"characters"`
+	Updated     int64   `json:"updated"`
+	PausedUntil int64   `json:"paused_until,omitempty"`
+}
+
+// NewSharedManager returns a manager whose buckets live in a state file in
+// dir, so that every translatego process draws from the same budgets. Each
+// reservation locks the file for its duration.
+func NewSharedManager(dir string) (*Manager, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	m := NewManager()
+	m.path = filepath.Join(dir, stateFileName)
+	return m, nil
+}
+
+// shared runs fn against buckets loaded from the state file and writes them
+// back, holding the file lock throughout. Without a state file, or when it
+// cannot be used, fn runs against the in-process buckets only.
+func (m *Manager) shared(fn func()) {
+	if m.path == "" {
+		fn()
+		return
+	}
+
+	unlock, err := state.Lock(m.path)
+	if err != nil {
+		fn()
+		return
+	}
+	defer unlock()
+
+	m.load()
+	fn()
+	_ = m.save()
+}
+
+func (m *Manager) load() {
+	data, err := os.ReadFile(m.path)
+	if err != nil {
+		return
+	}
+
+	var buckets map[string]bucketState
+	if err := json.Unmarshal(data, &buckets); err != nil {
+		return
+	}
+
+	for name, bucket := range buckets {
+		limiter := m.limiter(name)
+		limiter.requests = math.Min(bucket.Requests, float64(limiter.limits.Burst))
+		limiter.characters = math.Min(bucket.Characters, float64(limiter.limits.Characters))
+		limiter.updated = time.Unix(0, bucket.Updated)
+		limiter.pausedUntil = time.Time{}
+		if bucket.PausedUntil > 0 {
+			limiter.pausedUntil = time.Unix(0, bucket.PausedUntil)
+		}
+	}
+}
+
+func (m *Manager) save() error {
+	buckets := make(map[string]bucketState, len(m.limiters))
+	for name, limiter := range m.limiters {
+		bucket := bucketState{
+			Requests:   limiter.requests,
+			Characters: limiter.characters,
+			Updated:    limiter.updated.UnixNano(),
+		}
+		if !limiter.pausedUntil.IsZero() {
+			bucket.PausedUntil = limiter.pausedUntil.UnixNano()
+		}
+		buckets[name] = bucket
+	}
+
+	data, err := json.Marshal(buckets)
+	if err != nil {
+		return fmt.Errorf("failed to encode rate limit state: %w", err)
+	}
+	return state.WriteFile(m.path, data)
+}
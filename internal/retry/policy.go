@@ -0,0 +1,141 @@
+package retry
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy is an exponential backoff: retry n waits Base*Multiplier^(n-1),
+// capped at Max, randomly spread by ±Jitter of itself. MaxAttempts is the
+// number of retries after the first try.
+type Policy struct {
+	MaxAttempts int
+	Base        time.Duration
+	Max         time.Duration
+	Multiplier  float64
+	Jitter      float64
+}
+
+// Override changes the fields of a Policy that are set.
+type Override struct {
+	MaxAttempts *int
+	Base        *time.Duration
+	Max         *time.Duration
+	Multiplier  *float64
+	Jitter      *float64
+}
+
+// ProviderOverride adjusts the policy of one provider, in general and per
+// error type.
+type ProviderOverride struct {
+	Override
+	ErrorTypes map[string]Override
+}
+
+// Policies resolves the policy for a provider and error type. Overrides
+// apply in order: provider, error type, provider's error type.
+type Policies struct {
+	Default    Policy
+	ErrorTypes map[string]Override
+	Providers  map[string]ProviderOverride
+}
+
+// DefaultPolicies retries up to maxAttempts times starting at one second,
+// never retries errors that need the user to act, and waits longer on rate
+// limits.
+func DefaultPolicies(maxAttempts int) Policies {
+	none := 0
+	rateBase, rateMax := 5*time.Second, time.Minute
+
+	return Policies{
+		Default: Policy{
+			MaxAttempts: maxAttempts,
+			Base:        time.Second,
+			Max:         30 * time.Second,
+			Multiplier:  2,
+			Jitter:      0.2,
+		},
+		ErrorTypes: map[string]Override{
+			"UNAUTHORIZED":   {MaxAttempts: &none},
+			"FORBIDDEN":      {MaxAttempts: &none},
+			"LANGUAGE_ERROR": {MaxAttempts: &none},
+			"QUOTA_EXCEEDED": {MaxAttempts: &none},
+			"CIRCUIT_OPEN":   {MaxAttempts: &none},
+			"RATE_LIMIT":     {Base: &rateBase, Max: &rateMax},
+		},
+		Providers: make(map[string]ProviderOverride),
+	}
+}
+
+func (ps Policies) For(provider, errorType string) Policy {
+	policy := ps.Default
+	providerOverride := ps.Providers[provider]
+
+	policy = policy.With(providerOverride.Override)
+	if override, exists := ps.ErrorTypes[errorType]; exists {
+		policy = policy.With(override)
+	}
+	if override, exists := providerOverride.ErrorTypes[errorType]; exists {
+		policy = policy.With(override)
+	}
+	return policy
+}
+
+func (p Policy) With(o Override) Policy {
+	if o.MaxAttempts != nil {
+		p.MaxAttempts = *o.MaxAttempts
+	}
+	if o.Base != nil {
+		p.Base = *o.Base
+	}
+	if o.Max != nil {
+		p.Max = *o.Max
+	}
+	if o.Multiplier != nil {
+		p.Multiplier = *o.Multiplier
+	}
+	if o.Jitter != nil {
+		p.Jitter = *o.Jitter
+	}
+	return p
+}
+
+// Merge returns o with the fields set in top replaced.
+func (o Override) Merge(top Override) Override {
+	if top.MaxAttempts != nil {
+		o.MaxAttempts = top.MaxAttempts
+	}
+	if top.Base != nil {
+		o.Base = top.Base
+	}
+	if top.Max != nil {
+		o.Max = top.Max
+	}
+	if top.Multiplier != nil {
+		o.Multiplier = top.Multiplier
+	}
+	if top.Jitter != nil {
+		o.Jitter = top.Jitter
+	}
+	return o
+}
+
+// Delay returns how long to wait before retry number attempt, counting from 1.
+func (p Policy) Delay(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.Base) * math.Pow(multiplier, float64(max(attempt-1, 0)))
+	if p.Max > 0 {
+		delay = math.Min(delay, float64(p.Max))
+	}
+
+	if jitter := math.Min(math.Max(p.Jitter, 0), 1); jitter > 0 {
+		delay *= 1 + jitter*(2*rand.Float64()-1)
+	}
+
+	return time.Duration(delay)
+}
@@ -0,0 +1,128 @@
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDelayBackoffAndCap(t *testing.T) {
+	policy := Policy{Base: time.Second, Max: 30 * time.Second, Multiplier: 2}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, time.Second},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{5, 16 * time.Second},
+		{6, 30 * time.Second},
+		{50, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Delay(tt.attempt); got != tt.want {
+			t.Errorf("Delay(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestDelayEdgeCases(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		attempt int
+		want    time.Duration
+	}{
+		{"multiplier below 1 stays flat", Policy{Base: time.Second, Multiplier: 0.5}, 4, time.Second},
+		{"no cap", Policy{Base: time.Second, Multiplier: 10}, 4, 1000 * time.Second},
+		{"negative jitter is ignored", Policy{Base: time.Second, Multiplier: 2, Jitter: -1}, 2, 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := tt.policy.Delay(tt.attempt); got != tt.want {
+			t.Errorf("%s: Delay(%d) = %v, want %v", tt.name, tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestDelayJitterBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   Policy
+		attempt  int
+		min, max time.Duration
+	}{
+		{
+			name:    "spread around the backoff",
+			policy:  Policy{Base: time.Second, Max: 30 * time.Second, Multiplier: 2, Jitter: 0.2},
+			attempt: 3,
+			min:     3200 * time.Millisecond,
+			max:     4800 * time.Millisecond,
+		},
+		{
+			name:    "jitter applies after the cap",
+			policy:  Policy{Base: time.Second, Max: 30 * time.Second, Multiplier: 2, Jitter: 0.2},
+			attempt: 20,
+			min:     24 * time.Second,
+			max:     36 * time.Second,
+		},
+		{
+			name:    "jitter above 1 is clamped",
+			policy:  Policy{Base: time.Second, Multiplier: 2, Jitter: 5},
+			attempt: 1,
+			min:     0,
+			max:     2 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spread := false
+			first := tt.policy.Delay(tt.attempt)
+			for i := 0; i < 1000; i++ {
+				got := tt.policy.Delay(tt.attempt)
+				if got < tt.min || got > tt.max {
+					t.Fatalf("Delay(%d) = %v, want within [%v, %v]", tt.attempt, got, tt.min, tt.max)
+				}
+				spread = spread || got != first
+			}
+			if !spread {
+				t.Errorf("Delay(%d) always returned %v, want jitter", tt.attempt, first)
+			}
+		})
+	}
+}
+
+func TestPoliciesFor(t *testing.T) {
+	attempts := func(n int) *int { return &n }
+	policies := DefaultPolicies(3)
+	policies.Providers["DEEPL"] = ProviderOverride{
+		Override: Override{MaxAttempts: attempts(5)},
+		ErrorTypes: map[string]Override{
+			"RATE_LIMIT": {MaxAttempts: attempts(1)},
+		},
+	}
+
+	tests := []struct {
+		provider, errorType string
+		wantAttempts        int
+		wantBase            time.Duration
+	}{
+		{"GOOGLE", "NETWORK_ERROR", 3, time.Second},
+		{"GOOGLE", "UNAUTHORIZED", 0, time.Second},
+		{"GOOGLE", "RATE_LIMIT", 3, 5 * time.Second},
+		{"DEEPL", "NETWORK_ERROR", 5, time.Second},
+		{"DEEPL", "UNAUTHORIZED", 0, time.Second},
+		{"DEEPL", "RATE_LIMIT", 1, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		policy := policies.For(tt.provider, tt.errorType)
+		if policy.MaxAttempts != tt.wantAttempts || policy.Base != tt.wantBase {
+			t.Errorf("For(%s, %s) = %d attempts from %v, want %d from %v",
+				tt.provider, tt.errorType, policy.MaxAttempts, policy.Base, tt.wantAttempts, tt.wantBase)
+		}
+	}
+}
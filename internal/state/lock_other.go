@@ -0,0 +1,14 @@
+//go:build !unix && !windows
+
+package state
+
+import "os"
+
+// Platforms without file locking fall back to unlocked access.
+func lockFile(file *os.File) error {
+	return nil
+}
+
+func unlockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,136 @@
+// Package state locates and locks the files that translatego processes share,
+// such as quota usage and rate limiter buckets.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// DefaultDir returns $XDG_STATE_HOME/translatego, falling back to
+// ~/.local/state/translatego, or %LOCALAPPDATA%\translatego on Windows.
+func DefaultDir() (string, error) {
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+			return filepath.Join(dir, "translatego"), nil
+		}
+	}
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "translatego"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate state directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", "translatego"), nil
+}
+
+// Lock blocks until it holds an exclusive lock on path+".lock" and returns a
+// function that releases it. The lock file is separate from path so that path
+// itself can be replaced atomically while the lock is held.
+func Lock(path string) (func() error, error) {
+	file, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", filepath.Base(path), err)
+	}
+
+	return func() error {
+		unlockErr := unlockFile(file)
+		if err := file.Close(); unlockErr == nil {
+			unlockErr = err
+		}
+		return unlockErr
+	}, nil
+}
+
+// WriteFile atomically replaces path with data.
+func WriteFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// Load locks path and decodes its JSON content into v, leaving v as is when
+// the file does not exist yet.
+func Load(path string, v any) error {
+	unlock, err := Lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return read(path, v)
+}
+
+// Update locks path, decodes its JSON content into v (leaving v as is when the
+// file does not exist yet), runs fn and writes v back. ran reports whether fn
+// was called, so that callers do not apply a change twice when only the write
+// failed. Content that cannot be parsed is replaced; the error then wraps
+// ErrCorrupt even though fn ran and v was written.
+func Update(path string, v any, fn func()) (ran bool, err error) {
+	unlock, err := Lock(path)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	readErr := read(path, v)
+	if readErr != nil && !errors.Is(readErr, ErrCorrupt) {
+		return false, readErr
+	}
+
+	fn()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return true, fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+	if err := WriteFile(path, data); err != nil {
+		return true, err
+	}
+	if readErr != nil {
+		return true, fmt.Errorf("%w; replaced it", readErr)
+	}
+	return true, nil
+}
+
+// ErrCorrupt is wrapped by errors about state files that cannot be parsed.
+var ErrCorrupt = errors.New("corrupt state file")
+
+func read(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w: %w", filepath.Base(path), ErrCorrupt, err)
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package utils
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// Sentinel errors matched by every ServiceError of the corresponding type, so
+// that callers can use errors.Is instead of comparing ErrorType strings.
+var (
+	ErrTimeout         = errors.New("translate: request timed out")
+	ErrRateLimited     = errors.New("translate: rate limit exceeded")
+	ErrUnauthorized    = errors.New("translate: invalid or missing API key")
+	ErrForbidden       = errors.New("translate: access forbidden")
+	ErrNotFound        = errors.New("translate: endpoint not found")
+	ErrServerError     = errors.New("translate: server error")
+	ErrServiceDown     = errors.New("translate: service unavailable")
+	ErrNetwork         = errors.New("translate: network error")
+	ErrLanguage        = errors.New("translate: unsupported language")
+	ErrQuotaExceeded   = errors.New("translate: quota exceeded")
+	ErrCircuitOpen     = errors.New("translate: circuit open")
+	ErrInvalidResponse = errors.New("translate: invalid response")
+)
+
+var sentinels = map[string]error{
+	ErrorTypeTimeout:         ErrTimeout,
+	ErrorTypeRateLimit:       ErrRateLimited,
+	ErrorTypeUnauthorized:    ErrUnauthorized,
+	ErrorTypeForbidden:       ErrForbidden,
+	ErrorTypeNotFound:        ErrNotFound,
+	ErrorTypeServerError:     ErrServerError,
+	ErrorTypeServiceDown:     ErrServiceDown,
+	ErrorTypeNetworkError:    ErrNetwork,
+	ErrorTypeLanguageError:   ErrLanguage,
+	ErrorTypeQuotaExceeded:   ErrQuotaExceeded,
+	ErrorTypeCircuitOpen:     ErrCircuitOpen,
+	ErrorTypeInvalidResponse: ErrInvalidResponse,
+}
+
+// Unwrap exposes the sentinel of the error type and the underlying cause.
+func (e *ServiceError) Unwrap() []error {
+	var errs []error
+	if sentinel, exists := sentinels[e.ErrorType]; exists {
+		errs = append(errs, sentinel)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// classifyStatus fills in the type, message and suggestion for an HTTP status
+// code. It reports false for codes it has nothing to say about.
+func classifyStatus(serviceErr *ServiceError, statusCode int) bool {
+	switch {
+	case statusCode == 429:
+		serviceErr.ErrorType = ErrorTypeRateLimit
+		serviceErr.Message = "Rate limit exceeded"
+		serviceErr.Suggestion = "Wait a moment before trying again"
+		serviceErr.IsRetryable = true
+
+	case statusCode == 401:
+		serviceErr.ErrorType = ErrorTypeUnauthorized
+		serviceErr.Message = "Invalid or missing API key"
+		serviceErr.Suggestion = "Check your API key configuration"
+
+	case statusCode == 403:
+		serviceErr.ErrorType = ErrorTypeForbidden
+		serviceErr.Message = "Access forbidden"
+		serviceErr.Suggestion = "API key may be invalid or service unavailable"
+
+	case statusCode == 404:
+		serviceErr.ErrorType = ErrorTypeNotFound
+		serviceErr.Message = "Service endpoint not found"
+		serviceErr.Suggestion = "Service may be temporarily unavailable"
+
+	case statusCode == 456:
+		// DeepL answers 456 once the character quota is used up.
+		serviceErr.ErrorType = ErrorTypeQuotaExceeded
+		serviceErr.Message = "Quota exceeded"
+		serviceErr.Suggestion = "Wait for the quota to reset or upgrade your plan"
+
+	case statusCode == 502 || statusCode == 503 || statusCode == 504:
+		serviceErr.ErrorType = ErrorTypeServiceDown
+		serviceErr.Message = fmt.Sprintf("Service temporarily unavailable (HTTP %d)", statusCode)
+		serviceErr.Suggestion = "Service is down for maintenance, try again later"
+		serviceErr.IsRetryable = true
+
+	case statusCode >= 500 && statusCode < 600:
+		serviceErr.ErrorType = ErrorTypeServerError
+		serviceErr.Message = fmt.Sprintf("Server error (HTTP %d)", statusCode)
+		serviceErr.Suggestion = "Service is experiencing issues, try again later"
+		serviceErr.IsRetryable = true
+
+	default:
+		return false
+	}
+	return true
+}
+
+// classifyCause fills in the type, message and suggestion for a Go error
+// returned while talking to a provider. It reports false for errors that are
+// not about the request or the connection.
+func classifyCause(serviceErr *ServiceError, err error) bool {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCert x509.CertificateInvalidError
+	var recordErr tls.RecordHeaderError
+	var opErr *net.OpError
+	var urlErr *url.Error
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		serviceErr.ErrorType = ErrorTypeTimeout
+		serviceErr.Message = "Request timed out"
+		serviceErr.Suggestion = "Check your internet connection or try again"
+		serviceErr.IsRetryable = true
+
+	case errors.As(err, &dnsErr):
+		serviceErr.ErrorType = ErrorTypeNetworkError
+		serviceErr.Message = fmt.Sprintf("Could not resolve %s", dnsErr.Name)
+		serviceErr.Suggestion = "Check your internet connection and DNS settings"
+		serviceErr.IsRetryable = dnsErr.IsTimeout || dnsErr.IsTemporary || !dnsErr.IsNotFound
+
+	case errors.As(err, &certErr), errors.As(err, &unknownAuthority),
+		errors.As(err, &hostnameErr), errors.As(err, &invalidCert), errors.As(err, &recordErr):
+		serviceErr.ErrorType = ErrorTypeNetworkError
+		serviceErr.Message = "Secure connection failed (TLS)"
+		serviceErr.Suggestion = "Check the system clock, proxy and certificates"
+
+	case errors.As(err, &netErr) && netErr.Timeout():
+		serviceErr.ErrorType = ErrorTypeTimeout
+		serviceErr.Message = "Request timed out"
+		serviceErr.Suggestion = "Check your internet connection or try again"
+		serviceErr.IsRetryable = true
+
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET):
+		serviceErr.ErrorType = ErrorTypeServiceDown
+		serviceErr.Message = "Connection refused or reset"
+		serviceErr.Suggestion = "Service may be down, try again later"
+		serviceErr.IsRetryable = true
+
+	case errors.As(err, &opErr), errors.As(err, &urlErr):
+		serviceErr.ErrorType = ErrorTypeNetworkError
+		serviceErr.Message = "Network connection failed"
+		serviceErr.Suggestion = "Check your internet connection"
+		serviceErr.IsRetryable = true
+
+	default:
+		return false
+	}
+	return true
+}
+
+// classifyBody refines an error from what the provider wrote in the response
+// body, which is more precise than the status code where the provider
+// documents its errors.
+func classifyBody(serviceErr *ServiceError, body string) {
+	switch serviceErr.Service {
+	case "OPENAI", "OPENROUTER":
+		var data struct {
+			Error struct {
+				Message string `json:"message"`
+				Type    string `json:"type"`
+				Code    any    `json:"code"`
+			} `json:"error"`
+		}
+		if json.Unmarshal([]byte(body), &data) != nil || data.Error.Message == "" {
+			return
+		}
+		serviceErr.Message = data.Error.Message
+
+		code, _ := data.Error.Code.(string)
+		if code == "" {
+			code = data.Error.Type
+		}
+		switch code {
+		case "invalid_api_key", "invalid_authentication":
+			serviceErr.ErrorType = ErrorTypeUnauthorized
+			serviceErr.IsRetryable = false
+		case "insufficient_quota", "billing_hard_limit_reached":
+			serviceErr.ErrorType = ErrorTypeQuotaExceeded
+			serviceErr.Suggestion = "Check your plan and billing details"
+			serviceErr.IsRetryable = false
+		case "rate_limit_exceeded":
+			serviceErr.ErrorType = ErrorTypeRateLimit
+			serviceErr.IsRetryable = true
+		case "model_not_found":
+			serviceErr.ErrorType = ErrorTypeNotFound
+			serviceErr.Suggestion = "Check the configured model"
+			serviceErr.IsRetryable = false
+		case "context_length_exceeded":
+			serviceErr.ErrorType = ErrorTypeUnknown
+			serviceErr.Suggestion = "Translate a shorter text"
+			serviceErr.IsRetryable = false
+		}
+
+	case "DEEPL":
+		var data struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal([]byte(body), &data) != nil || data.Message == "" {
+			return
+		}
+		serviceErr.Message = data.Message
+
+		message := strings.ToLower(data.Message)
+		switch {
+		case strings.Contains(message, "quota exceeded"):
+			serviceErr.ErrorType = ErrorTypeQuotaExceeded
+			serviceErr.IsRetryable = false
+		case strings.Contains(message, "authorization failure"), strings.Contains(message, "wrong endpoint"):
+			serviceErr.ErrorType = ErrorTypeUnauthorized
+			serviceErr.IsRetryable = false
+		case strings.Contains(message, "target_lang"), strings.Contains(message, "source_lang"):
+			serviceErr.ErrorType = ErrorTypeLanguageError
+			serviceErr.Suggestion = "Check source and target language settings"
+			serviceErr.IsRetryable = false
+		}
+	}
+}
+
+// myMemoryError turns a MyMemory reply that reports a failure with HTTP 200
+// into an error, or returns nil for a real translation.
+func myMemoryError(data map[string]interface{}) *ServiceError {
+	status := fmt.Sprint(data["responseStatus"])
+	if status == "200" || status == "<nil>" {
+		return nil
+	}
+
+	message := fmt.Sprint(data["responseDetails"])
+	if resp, ok := data["responseData"].(map[string]interface{}); ok && (message == "" || message == "<nil>") {
+		message = fmt.Sprint(resp["translatedText"])
+	}
+
+	serviceErr := &ServiceError{
+		Service:    "MYMEMORY",
+		ErrorType:  ErrorTypeUnknown,
+		Message:    message,
+		Suggestion: "Try a different service or check input text",
+	}
+
+	upper := strings.ToUpper(message)
+	switch {
+	case strings.Contains(upper, "DISTINCT LANGUAGES"), strings.Contains(upper, "INVALID LANGUAGE PAIR"),
+		strings.Contains(upper, "IS AN INVALID TARGET LANGUAGE"), strings.Contains(upper, "IS AN INVALID SOURCE LANGUAGE"):
+		serviceErr.ErrorType = ErrorTypeLanguageError
+		serviceErr.Suggestion = "Check source and target language settings"
+	case strings.Contains(upper, "ALL AVAILABLE FREE TRANSLATIONS"):
+		serviceErr.ErrorType = ErrorTypeQuotaExceeded
+		serviceErr.Suggestion = "MyMemory has daily limits for anonymous users"
+	case status == "429":
+		serviceErr.ErrorType = ErrorTypeRateLimit
+		serviceErr.IsRetryable = true
+	}
+	return serviceErr
+}
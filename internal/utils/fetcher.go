@@ -5,10 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
-	"strings"
 	"time"
+
+	"translatego/internal/languages"
+	"translatego/internal/ratelimit"
 )
 
 type ServiceConfig struct {
@@ -19,6 +22,38 @@ type ServiceConfig struct {
 	Body    []byte
 }
 
+// PromptVersion identifies the LLM prompt template below. Bump it whenever the
+// prompt changes so cached answers from the old prompt are not reused.
+const PromptVersion = "2"
+
+// AutoDetect is the source language that asks the provider to detect it.
+const AutoDetect = "auto"
+
+// SupportsAutoDetect reports whether the provider detects the source language
+// itself when sent AutoDetect.
+func SupportsAutoDetect(serviceName string) bool {
+	switch serviceName {
+	case "REVERSO", "REVERSO2":
+		return false
+	default:
+		return true
+	}
+}
+
+// maxErrorBody bounds how much of an error response is read to classify it.
+const maxErrorBody = 64 << 10
+
+func ProviderModel(serviceName string) string {
+	switch serviceName {
+	case "OPENAI":
+		return "gpt-3.5-turbo"
+	case "OPENROUTER":
+		return "deepseek/deepseek-chat-v3.1:free"
+	default:
+		return ""
+	}
+}
+
 type Result struct {
 	Name   string
 	URL    string
@@ -28,7 +63,7 @@ type Result struct {
 
 var client = &http.Client{Timeout: 5 * time.Second}
 
-func CheckService(cfg ServiceConfig) Result {
+func CheckService(ctx context.Context, cfg ServiceConfig) Result {
 	var req *http.Request
 	var err error
 
@@ -67,9 +102,9 @@ func CheckService(cfg ServiceConfig) Result {
 	}
 
 	if len(body) > 0 {
-		req, err = http.NewRequest(cfg.Method, checkURL, bytes.NewBuffer(body))
+		req, err = http.NewRequestWithContext(ctx, cfg.Method, checkURL, bytes.NewBuffer(body))
 	} else {
-		req, err = http.NewRequest(cfg.Method, checkURL, nil)
+		req, err = http.NewRequestWithContext(ctx, cfg.Method, checkURL, nil)
 	}
 	if err != nil {
 		return Result{Name: cfg.Name, URL: checkURL, Err: err}
@@ -92,7 +127,28 @@ func CheckService(cfg ServiceConfig) Result {
 	return Result{Name: cfg.Name, URL: checkURL, Status: res.StatusCode}
 }
 
-func TranslateService(cfg ServiceConfig, text, source, target string) (string, error) {
+func TranslateService(ctx context.Context, cfg ServiceConfig, text, source, target string) (string, error) {
+	return TranslateWithClient(ctx, client, cfg, text, source, target)
+}
+
+func TranslateWithClient(parent context.Context, httpClient *http.Client, cfg ServiceConfig, text, source, target string) (string, error) {
+	return TranslateObserved(parent, httpClient, cfg, text, source, target, Hooks{})
+}
+
+// Hooks receive what a provider reports besides the translation. Either
+// function may be nil.
+type Hooks struct {
+	RateLimit func(ratelimit.Feedback)
+	Usage     func(tokens int)
+}
+
+// TranslateObserved is TranslateWithClient that also passes the rate limit
+// headers and token usage of the provider's response to hooks.
+func TranslateObserved(parent context.Context, httpClient *http.Client, cfg ServiceConfig, text, source, target string, hooks Hooks) (string, error) {
+	if httpClient == nil {
+		httpClient = client
+	}
+
 	if source == target {
 		if source == "en" {
 			target = "ru"
@@ -102,12 +158,10 @@ func TranslateService(cfg ServiceConfig, text, source, target string) (string, e
 	}
 
 	sourceCode := source
-	targetCode := target
-
-	if cfg.Name == "REVERSO" || cfg.Name == "REVERSO2" {
-		sourceCode = convertToReversoLangCode(source)
-		targetCode = convertToReversoLangCode(target)
+	if source != AutoDetect {
+		sourceCode = languages.SourceCode(cfg.Name, source)
 	}
+	targetCode := languages.Code(cfg.Name, target)
 
 	var body []byte
 	var finalURL string = cfg.URL
@@ -115,25 +169,31 @@ func TranslateService(cfg ServiceConfig, text, source, target string) (string, e
 	case "GOOGLE":
 		body = []byte(fmt.Sprintf(`{"message":"%s","from":"%s","to":"%s"}`, text, sourceCode, targetCode))
 	case "DEEPL":
-		body = []byte(fmt.Sprintf(`{"text":"%s","source_lang":"%s","target_lang":"%s"}`, text, strings.ToUpper(sourceCode), strings.ToUpper(targetCode)))
+		if sourceCode == AutoDetect {
+			body = []byte(fmt.Sprintf(`{"text":"%s","target_lang":"%s"}`, text, targetCode))
+		} else {
+			body = []byte(fmt.Sprintf(`{"text":"%s","source_lang":"%s","target_lang":"%s"}`, text, sourceCode, targetCode))
+		}
 	case "REVERSO":
 		body = []byte(fmt.Sprintf(`{"format":"text","from":"%s","to":"%s","input":"%s"}`, sourceCode, targetCode, text))
 	case "REVERSO2":
 		body = []byte(fmt.Sprintf(`{"format":"text","from":"%s","to":"%s","input":"%s","options":{"sentenceSplitter":true,"origin":"translation.web","contextResults":false,"languageDetection":false}}`, sourceCode, targetCode, text))
 	case "MYMEMORY":
+		if sourceCode == AutoDetect {
+			sourceCode = "autodetect"
+		}
 		finalURL = fmt.Sprintf("https://api.mymemory.translated.net/get?q=%s&langpair=%s|%s", url.QueryEscape(text), sourceCode, targetCode)
 	case "LINGVA":
-		finalURL = fmt.Sprintf("https://lingva.thedaviddelta.com/api/v1/%s/%s/%s", sourceCode, targetCode, url.QueryEscape(text))
-	case "OPENAI":
-		body = []byte(fmt.Sprintf(`{
-			"model": "gpt-3.5-turbo",
-			"messages": [{"role":"user","content":"Translate '%s' from %s to %s. Return only the translation, no additional text."}]
-		}`, text, getLanguageName(sourceCode), getLanguageName(targetCode)))
-	case "OPENROUTER":
+		finalURL = fmt.Sprintf("https://lingva.thedaviddelta.com/api/v1/%s/%s/%s", sourceCode, targetCode, url.PathEscape(text))
+	case "OPENAI", "OPENROUTER":
+		from := fmt.Sprintf(" from %s", languages.EnglishName(sourceCode))
+		if sourceCode == AutoDetect {
+			from = ""
+		}
 		body = []byte(fmt.Sprintf(`{
-			"model": "deepseek/deepseek-chat-v3.1:free",
-			"messages": [{"role":"user","content":"Translate '%s' from %s to %s. Return only the translation, no additional text."}]
-		}`, text, getLanguageName(sourceCode), getLanguageName(targetCode)))
+			"model": "%s",
+			"messages": [{"role":"user","content":"Translate '%s'%s to %s. Return only the translation, no additional text."}]
+		}`, ProviderModel(cfg.Name), text, from, languages.EnglishName(targetCode)))
 	}
 
 	var req *http.Request
@@ -159,29 +219,19 @@ func TranslateService(cfg ServiceConfig, text, source, target string) (string, e
 		timeout = 45 * time.Second
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(parent, timeout)
 	defer cancel()
 	req = req.WithContext(ctx)
 
-	res, err := client.Do(req)
+	res, err := httpClient.Do(req)
 	if err != nil {
-		var serviceErr *ServiceError
+		if parentErr := parent.Err(); parentErr != nil {
+			return "", parentErr
+		}
+
+		serviceErr := CreateServiceError(cfg.Name, err, 0)
 		if ctx.Err() == context.DeadlineExceeded {
-			serviceErr = &ServiceError{
-				Service:     cfg.Name,
-				ErrorType:   ErrorTypeTimeout,
-				Message:     fmt.Sprintf("Request timed out after %v", timeout),
-				Suggestion:  "Check your internet connection or try again",
-				IsRetryable: true,
-			}
-		} else {
-			serviceErr = &ServiceError{
-				Service:     cfg.Name,
-				ErrorType:   ErrorTypeNetworkError,
-				Message:     fmt.Sprintf("Network error: %v", err),
-				Suggestion:  "Check your internet connection",
-				IsRetryable: true,
-			}
+			serviceErr.Message = fmt.Sprintf("Request timed out after %v", timeout)
 		}
 		return "", serviceErr
 	}
@@ -192,45 +242,30 @@ func TranslateService(cfg ServiceConfig, text, source, target string) (string, e
 		}
 	}()
 
+	feedback := ratelimit.ParseHeaders(res.Header, time.Now())
+	if hooks.RateLimit != nil {
+		hooks.RateLimit(feedback)
+	}
+
 	if res.StatusCode != http.StatusOK {
 		statusErr := &ServiceError{
-			Service:     cfg.Name,
-			StatusCode:  res.StatusCode,
-			IsRetryable: res.StatusCode >= 500 || res.StatusCode == 429,
-		}
-
-		switch res.StatusCode {
-		case 429:
-			statusErr.ErrorType = ErrorTypeRateLimit
-			statusErr.Message = "Rate limit exceeded"
-			statusErr.Suggestion = "Wait a moment before trying again"
-		case 401:
-			statusErr.ErrorType = ErrorTypeUnauthorized
-			statusErr.Message = "Invalid or missing API key"
-			statusErr.Suggestion = "Check your API key configuration"
-		case 403:
-			statusErr.ErrorType = ErrorTypeForbidden
-			statusErr.Message = "Access forbidden"
-			statusErr.Suggestion = "API key may be invalid or service unavailable"
-		case 404:
-			statusErr.ErrorType = ErrorTypeNotFound
-			statusErr.Message = "Service endpoint not found"
-			statusErr.Suggestion = "Service may be temporarily unavailable"
-		case 503:
-			statusErr.ErrorType = ErrorTypeServiceDown
-			statusErr.Message = "Service temporarily unavailable"
-			statusErr.Suggestion = "Service is down for maintenance"
-		default:
-			if res.StatusCode >= 500 {
-				statusErr.ErrorType = ErrorTypeServerError
-				statusErr.Message = fmt.Sprintf("Server error (HTTP %d)", res.StatusCode)
-				statusErr.Suggestion = "Service is experiencing issues"
-			} else {
-				statusErr.ErrorType = ErrorTypeUnknown
-				statusErr.Message = fmt.Sprintf("HTTP error %d", res.StatusCode)
-				statusErr.Suggestion = "Check service documentation"
+			Service:    cfg.Name,
+			StatusCode: res.StatusCode,
+		}
+		if !classifyStatus(statusErr, res.StatusCode) {
+			statusErr.ErrorType = ErrorTypeUnknown
+			statusErr.Message = fmt.Sprintf("HTTP error %d", res.StatusCode)
+			statusErr.Suggestion = "Check service documentation"
+		}
+		if res.StatusCode == 429 {
+			statusErr.RetryAfter = feedback.RetryAfter
+			if feedback.Remaining == 0 {
+				statusErr.RetryAfter = max(statusErr.RetryAfter, feedback.Reset)
 			}
 		}
+		if errorBody, err := io.ReadAll(io.LimitReader(res.Body, maxErrorBody)); err == nil {
+			classifyBody(statusErr, string(errorBody))
+		}
 
 		return "", statusErr
 	}
@@ -238,6 +273,10 @@ func TranslateService(cfg ServiceConfig, text, source, target string) (string, e
 	buf := new(bytes.Buffer)
 	buf.Grow(8192) // Pre-allocate buffer for better performance
 	if _, err := buf.ReadFrom(res.Body); err != nil {
+		if parentErr := parent.Err(); parentErr != nil {
+			return "", parentErr
+		}
+
 		serviceErr := &ServiceError{
 			Service:     cfg.Name,
 			ErrorType:   ErrorTypeNetworkError,
@@ -249,11 +288,24 @@ func TranslateService(cfg ServiceConfig, text, source, target string) (string, e
 	}
 	responseBody := buf.String()
 
-	switch cfg.Name {
+	trans, err := parseResponse(cfg.Name, responseBody, hooks)
+	if err != nil {
+		return "", err
+	}
+	if problem := bogusTranslation(trans); problem != "" {
+		return "", invalidResponse(cfg.Name, problem)
+	}
+	return trans, nil
+}
+
+// parseResponse extracts the translation from a provider's reply. Replies
+// without one are reported as invalid instead of shown as a translation.
+func parseResponse(serviceName, responseBody string, hooks Hooks) (string, error) {
+	switch serviceName {
 	case "GOOGLE":
 		var data map[string]interface{}
 		if err := json.Unmarshal([]byte(responseBody), &data); err != nil {
-			return "", err
+			return "", invalidResponse(serviceName, describeBody(responseBody))
 		}
 		if trans, ok := data["translation"].(map[string]interface{}); ok {
 			if res, ok := trans["trans_result"].(map[string]interface{}); ok {
@@ -262,65 +314,67 @@ func TranslateService(cfg ServiceConfig, text, source, target string) (string, e
 				}
 			}
 		}
-		return responseBody, nil
+		return "", invalidResponse(serviceName, describeBody(responseBody))
 	case "DEEPL":
 		var data map[string]interface{}
 		if err := json.Unmarshal([]byte(responseBody), &data); err != nil {
-			return "", err
+			return "", invalidResponse(serviceName, describeBody(responseBody))
 		}
 		if text, ok := data["data"].(string); ok {
 			return text, nil
 		}
-		return responseBody, nil
+		return "", invalidResponse(serviceName, describeBody(responseBody))
 	case "REVERSO":
 		var data map[string]interface{}
 		if err := json.Unmarshal([]byte(responseBody), &data); err != nil {
-			return "", err
+			return "", invalidResponse(serviceName, describeBody(responseBody))
 		}
 		if trans, ok := data["translation"].([]interface{}); ok && len(trans) > 0 {
 			if t, ok := trans[0].(string); ok {
 				return t, nil
 			}
 		}
-		return responseBody, nil
+		return "", invalidResponse(serviceName, describeBody(responseBody))
 	case "REVERSO2":
 		var data map[string]interface{}
 		if err := json.Unmarshal([]byte(responseBody), &data); err != nil {
-			return "", err
+			return "", invalidResponse(serviceName, describeBody(responseBody))
 		}
 		if trans, ok := data["translation"].([]interface{}); ok && len(trans) > 0 {
 			if t, ok := trans[0].(string); ok {
 				return t, nil
 			}
 		}
-		return responseBody, nil
+		return "", invalidResponse(serviceName, describeBody(responseBody))
 	case "MYMEMORY":
 		var data map[string]interface{}
 		if err := json.Unmarshal([]byte(responseBody), &data); err != nil {
-			return "", err
+			return "", invalidResponse(serviceName, describeBody(responseBody))
+		}
+		if serviceErr := myMemoryError(data); serviceErr != nil {
+			return "", serviceErr
 		}
 		if resp, ok := data["responseData"].(map[string]interface{}); ok {
 			if trans, ok := resp["translatedText"].(string); ok {
 				return trans, nil
 			}
 		}
-		return responseBody, nil
+		return "", invalidResponse(serviceName, describeBody(responseBody))
 	case "LINGVA":
 		var data map[string]interface{}
 		if err := json.Unmarshal([]byte(responseBody), &data); err != nil {
-			return "", err
+			return "", invalidResponse(serviceName, describeBody(responseBody))
 		}
 		if trans, ok := data["translation"].(string); ok {
-			decoded := strings.ReplaceAll(trans, "+", " ")
-			decoded = strings.Join(strings.Fields(decoded), " ")
-			return strings.TrimSpace(decoded), nil
+			return trans, nil
 		}
-		return responseBody, nil
+		return "", invalidResponse(serviceName, describeBody(responseBody))
 	case "OPENAI":
 		var data map[string]interface{}
 		if err := json.Unmarshal([]byte(responseBody), &data); err != nil {
-			return "", err
+			return "", invalidResponse(serviceName, describeBody(responseBody))
 		}
+		reportUsage(data, hooks)
 		if choices, ok := data["choices"].([]interface{}); ok && len(choices) > 0 {
 			if choice, ok := choices[0].(map[string]interface{}); ok {
 				if msg, ok := choice["message"].(map[string]interface{}); ok {
@@ -330,12 +384,13 @@ func TranslateService(cfg ServiceConfig, text, source, target string) (string, e
 				}
 			}
 		}
-		return responseBody, nil
+		return "", invalidResponse(serviceName, describeBody(responseBody))
 	case "OPENROUTER":
 		var data map[string]interface{}
 		if err := json.Unmarshal([]byte(responseBody), &data); err != nil {
-			return "", err
+			return "", invalidResponse(serviceName, describeBody(responseBody))
 		}
+		reportUsage(data, hooks)
 		if choices, ok := data["choices"].([]interface{}); ok && len(choices) > 0 {
 			if choice, ok := choices[0].(map[string]interface{}); ok {
 				if msg, ok := choice["message"].(map[string]interface{}); ok {
@@ -345,62 +400,36 @@ func TranslateService(cfg ServiceConfig, text, source, target string) (string, e
 				}
 			}
 		}
-		return responseBody, nil
+		return "", invalidResponse(serviceName, describeBody(responseBody))
 	default:
 		return responseBody, nil
 	}
 }
 
-func convertToReversoLangCode(langCode string) string {
-	switch langCode {
-	case "en":
-		return "eng"
-	case "ru":
-		return "rus"
-	case "de":
-		return "ger"
-	case "fr":
-		return "fra"
-	case "es":
-		return "spa"
-	case "it":
-		return "ita"
-	case "ja":
-		return "jpn"
-	case "zh":
-		return "chi"
-	case "ko":
-		return "kor"
-	case "ar":
-		return "ara"
-	default:
-		return langCode
+func reportUsage(data map[string]interface{}, hooks Hooks) {
+	if hooks.Usage == nil {
+		return
+	}
+	if usage, ok := data["usage"].(map[string]interface{}); ok {
+		if tokens, ok := usage["total_tokens"].(float64); ok {
+			hooks.Usage(int(tokens))
+		}
 	}
 }
 
-func getLanguageName(langCode string) string {
-	switch langCode {
-	case "en":
-		return "English"
-	case "ru":
-		return "Russian"
-	case "de":
-		return "German"
-	case "fr":
-		return "French"
-	case "es":
-		return "Spanish"
-	case "it":
-		return "Italian"
-	case "ja":
-		return "Japanese"
-	case "zh":
-		return "Chinese"
-	case "ko":
-		return "Korean"
-	case "ar":
-		return "Arabic"
-	default:
-		return langCode
+func WithAPIKey(cfg ServiceConfig, apiKey string) ServiceConfig {
+	if apiKey == "" {
+		return cfg
+	}
+
+	newHeaders := make(map[string]string)
+	for k, v := range cfg.Headers {
+		if k == "Authorization" {
+			newHeaders[k] = "Bearer " + apiKey
+		} else {
+			newHeaders[k] = v
+		}
 	}
+	cfg.Headers = newHeaders
+	return cfg
 }
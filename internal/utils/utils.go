@@ -1,10 +1,12 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
-	"regexp"
 	"strings"
-	"unicode"
+	"time"
+
+	"translatego/internal/detect"
 )
 
 type ServiceError struct {
@@ -14,6 +16,8 @@ type ServiceError struct {
 	Message     string
 	Suggestion  string
 	IsRetryable bool
+	RetryAfter  time.Duration
+	Err         error // Underlying cause, if any
 }
 
 func (e *ServiceError) Error() string {
@@ -21,128 +25,64 @@ func (e *ServiceError) Error() string {
 }
 
 const (
-	ErrorTypeTimeout       = "TIMEOUT"
-	ErrorTypeRateLimit     = "RATE_LIMIT"
-	ErrorTypeUnauthorized  = "UNAUTHORIZED"
-	ErrorTypeForbidden     = "FORBIDDEN"
-	ErrorTypeNotFound      = "NOT_FOUND"
-	ErrorTypeServerError   = "SERVER_ERROR"
-	ErrorTypeServiceDown   = "SERVICE_DOWN"
-	ErrorTypeNetworkError  = "NETWORK_ERROR"
-	ErrorTypeLanguageError = "LANGUAGE_ERROR"
-	ErrorTypeUnknown       = "UNKNOWN"
+	ErrorTypeTimeout         = "TIMEOUT"
+	ErrorTypeRateLimit       = "RATE_LIMIT"
+	ErrorTypeUnauthorized    = "UNAUTHORIZED"
+	ErrorTypeForbidden       = "FORBIDDEN"
+	ErrorTypeNotFound        = "NOT_FOUND"
+	ErrorTypeServerError     = "SERVER_ERROR"
+	ErrorTypeServiceDown     = "SERVICE_DOWN"
+	ErrorTypeNetworkError    = "NETWORK_ERROR"
+	ErrorTypeLanguageError   = "LANGUAGE_ERROR"
+	ErrorTypeQuotaExceeded   = "QUOTA_EXCEEDED"
+	ErrorTypeCircuitOpen     = "CIRCUIT_OPEN"
+	ErrorTypeInvalidResponse = "INVALID_RESPONSE"
+	ErrorTypeUnknown         = "UNKNOWN"
 )
 
+// CreateServiceError classifies err, and statusCode when it is known, into a
+// ServiceError. Errors that already are one are copied as they are.
 func CreateServiceError(serviceName string, err error, statusCode int) *ServiceError {
 	if err == nil {
 		return nil
 	}
 
-	errStr := strings.ToLower(err.Error())
+	var existing *ServiceError
+	if errors.As(err, &existing) {
+		copied := *existing
+		return &copied
+	}
+
 	serviceErr := &ServiceError{
 		Service:    serviceName,
 		StatusCode: statusCode,
+		Err:        err,
 	}
-
-	switch {
-	case strings.Contains(errStr, "timeout") || strings.Contains(errStr, "deadline exceeded"):
-		serviceErr.ErrorType = ErrorTypeTimeout
-		serviceErr.Message = "Request timed out"
-		serviceErr.Suggestion = "Check your internet connection or try again"
-		serviceErr.IsRetryable = true
-
-	case statusCode == 429 || strings.Contains(errStr, "rate limit"):
-		serviceErr.ErrorType = ErrorTypeRateLimit
-		serviceErr.Message = "Rate limit exceeded"
-		serviceErr.Suggestion = "Wait a moment before trying again"
-		serviceErr.IsRetryable = true
-
-	case statusCode == 401 || strings.Contains(errStr, "unauthorized"):
-		serviceErr.ErrorType = ErrorTypeUnauthorized
-		serviceErr.Message = "Invalid or missing API key"
-		serviceErr.Suggestion = "Check your API key configuration"
-		serviceErr.IsRetryable = false
-
-	case statusCode == 403 || strings.Contains(errStr, "forbidden"):
-		serviceErr.ErrorType = ErrorTypeForbidden
-		serviceErr.Message = "Access forbidden"
-		serviceErr.Suggestion = "API key may be invalid or service unavailable"
-		serviceErr.IsRetryable = false
-
-	case statusCode == 404:
-		serviceErr.ErrorType = ErrorTypeNotFound
-		serviceErr.Message = "Service endpoint not found"
-		serviceErr.Suggestion = "Service may be temporarily unavailable"
-		serviceErr.IsRetryable = true
-
-	case statusCode >= 500 && statusCode < 600:
-		serviceErr.ErrorType = ErrorTypeServerError
-		serviceErr.Message = fmt.Sprintf("Server error (HTTP %d)", statusCode)
-		serviceErr.Suggestion = "Service is experiencing issues, try again later"
-		serviceErr.IsRetryable = true
-
-	case statusCode == 503:
-		serviceErr.ErrorType = ErrorTypeServiceDown
-		serviceErr.Message = "Service temporarily unavailable"
-		serviceErr.Suggestion = "Service is down for maintenance, try again later"
-		serviceErr.IsRetryable = true
-
-	case strings.Contains(errStr, "connection refused") || strings.Contains(errStr, "network"):
-		serviceErr.ErrorType = ErrorTypeNetworkError
-		serviceErr.Message = "Network connection failed"
-		serviceErr.Suggestion = "Check your internet connection"
-		serviceErr.IsRetryable = true
-
-	case strings.Contains(errStr, "distinct languages") || strings.Contains(errStr, "unsupported language"):
-		serviceErr.ErrorType = ErrorTypeLanguageError
-		serviceErr.Message = "Language configuration issue"
-		serviceErr.Suggestion = "Check source and target language settings"
-		serviceErr.IsRetryable = false
-
-	default:
-		serviceErr.ErrorType = ErrorTypeUnknown
-		serviceErr.Message = err.Error()
-		serviceErr.Suggestion = "Try a different service or check input text"
-		serviceErr.IsRetryable = false
+	if statusCode != 0 && classifyStatus(serviceErr, statusCode) {
+		return serviceErr
+	}
+	if classifyCause(serviceErr, err) {
+		return serviceErr
 	}
 
+	serviceErr.ErrorType = ErrorTypeUnknown
+	serviceErr.Message = err.Error()
+	serviceErr.Suggestion = "Try a different service or check input text"
 	return serviceErr
 }
 
-func DetectFromLanguage(text string) string {
-	nonLangPattern := regexp.MustCompile(`[\s\n\r.,;:!?()\-\"']`)
-	cleanText := nonLangPattern.ReplaceAllString(text, "")
-
-	if len(cleanText) == 0 {
-		return "auto"
-	}
-
-	var latinCount, cyrillicCount, otherCount int
-
-	for _, r := range cleanText {
-		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
-			latinCount++
-		} else if (r >= 'а' && r <= 'я') || (r >= 'А' && r <= 'Я') || r == 'ё' || r == 'Ё' {
-			cyrillicCount++
-		} else if unicode.IsLetter(r) {
-			otherCount++
-		}
-	}
-
-	total := latinCount + cyrillicCount + otherCount
-	if total == 0 {
-		return "auto"
-	}
+// minDetectConfidence is how sure the detector must be before its guess is
+// used as the source language; below it providers detect the language.
+const minDetectConfidence = 0.6
 
-	if cyrillicCount > latinCount && cyrillicCount > otherCount {
-		return "ru"
-	} else if latinCount > cyrillicCount && latinCount > otherCount {
-		return "en"
-	} else if otherCount > latinCount && otherCount > cyrillicCount {
-		return "auto"
-	} else {
+// DetectFromLanguage guesses the language of text, or returns "auto" when it
+// cannot tell.
+func DetectFromLanguage(text string) string {
+	language, confidence := detect.Best(text)
+	if language == "" || confidence < minDetectConfidence {
 		return "auto"
 	}
+	return language
 }
 
 func DetectToLanguage(lang, selectedLanguage string) string {
@@ -163,10 +103,6 @@ func GetDetailedErrorMessage(serviceName string, err error) string {
 		return "Unknown error"
 	}
 
-	if serviceErr, ok := err.(*ServiceError); ok {
-		return FormatServiceError(serviceErr)
-	}
-
 	serviceErr := CreateServiceError(serviceName, err, 0)
 	return FormatServiceError(serviceErr)
 }
@@ -196,6 +132,12 @@ func FormatServiceError(err *ServiceError) string {
 		icon = "🌐"
 	case ErrorTypeLanguageError:
 		icon = "🗣️"
+	case ErrorTypeQuotaExceeded:
+		icon = "📉"
+	case ErrorTypeCircuitOpen:
+		icon = "🔌"
+	case ErrorTypeInvalidResponse:
+		icon = "🧩"
 	default:
 		icon = "❌"
 	}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"translatego/internal/languages"
+)
+
+// invalidResponse reports a reply that does not contain a usable translation.
+// Such replies are usually error pages, so they are worth another try.
+func invalidResponse(serviceName, problem string) *ServiceError {
+	return &ServiceError{
+		Service:     serviceName,
+		ErrorType:   ErrorTypeInvalidResponse,
+		Message:     problem,
+		Suggestion:  "The service may have changed its API; try another service",
+		IsRetryable: true,
+	}
+}
+
+// describeBody summarizes a reply the parser could not make sense of.
+func describeBody(body string) string {
+	if looksLikeHTML(body) {
+		return "Unexpected HTML page instead of a translation"
+	}
+
+	snippet := strings.Join(strings.Fields(body), " ")
+	if runes := []rune(snippet); len(runes) > 80 {
+		snippet = string(runes[:79]) + "…"
+	}
+	if snippet == "" {
+		return "Empty response"
+	}
+	return fmt.Sprintf("Unexpected response: %s", snippet)
+}
+
+// bogusTranslation returns why a parsed translation cannot be one, or an
+// empty string.
+func bogusTranslation(translation string) string {
+	switch {
+	case strings.TrimSpace(translation) == "":
+		return "Empty translation"
+	case looksLikeHTML(translation):
+		return "Unexpected HTML page instead of a translation"
+	default:
+		return ""
+	}
+}
+
+func looksLikeHTML(s string) bool {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.HasPrefix(s, "<!doctype html") || strings.HasPrefix(s, "<html") ||
+		strings.HasPrefix(s, "<head") || strings.HasPrefix(s, "<body")
+}
+
+// CheckTranslation returns a warning when a translation looks untranslated:
+// identical to the input, or written in a script the target language does
+// not use. Both happen legitimately for names and short words, so they are
+// warnings rather than errors.
+func CheckTranslation(text, translation, source, target string) string {
+	if source != target && !strings.EqualFold(source, "auto") &&
+		hasLetters(text) && len([]rune(strings.TrimSpace(text))) > 3 &&
+		strings.EqualFold(strings.Join(strings.Fields(text), " "), strings.Join(strings.Fields(translation), " ")) {
+		return "translation is identical to the input"
+	}
+
+	scripts := languages.ScriptTables(target)
+	if scripts == nil {
+		return ""
+	}
+
+	var letters, matching int
+	for _, r := range translation {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.In(r, scripts...) {
+			matching++
+		}
+	}
+	// Loanwords, names and units in another script are normal; a translation
+	// mostly in another script is not.
+	if letters >= 4 && matching*2 < letters {
+		return fmt.Sprintf("translation is not in the script used for %s", target)
+	}
+	return ""
+}
+
+func hasLetters(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,597 @@
+// Package translate is the public entry point to translatego's providers,
+// cache and rate limiting.
+package translate
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"translatego/internal/cache"
+	"translatego/internal/detect"
+	"translatego/internal/languages"
+	"translatego/internal/pool"
+	"translatego/internal/ratelimit"
+	"translatego/internal/utils"
+)
+
+type Client struct {
+	providers   []Provider
+	cache       *Cache
+	rateLimit   *RateLimiter
+	httpClient  *http.Client
+	keys        KeySource
+	strategy    Strategy
+	quorum      int
+	maxWait     time.Duration
+	memory      *Memory
+	quota       *Quota
+	pool        *pool.Pool
+	breaker     *Breaker
+	latency     *LatencyTracker
+	hedging     bool
+	postProcess map[string]PostProcessing
+}
+
+// DefaultMaxWait is how long a request waits for a rate limited provider
+// before giving up on it.
+const DefaultMaxWait = 30 * time.Second
+
+// job is a request resolved against the client defaults, as handed to every
+// provider.
+type job struct {
+	text     string
+	source   string // AutoDetect, or the language the user chose
+	detected string // source, or the local guess when it is AutoDetect
+	target   string
+	priority Priority
+	maxWait  time.Duration
+	onQueued func(provider string, start time.Time)
+}
+
+type Option func(*Client)
+
+func WithProviders(providers []Provider) Option {
+	return func(c *Client) {
+		c.providers = providers
+	}
+}
+
+// WithCache sets the cache used for lookups and stores. A nil cache disables
+// caching.
+func WithCache(cache *Cache) Option {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// WithRateLimiter sets the per-provider limiter. A nil limiter disables rate
+// limiting.
+func WithRateLimiter(rateLimit *RateLimiter) Option {
+	return func(c *Client) {
+		c.rateLimit = rateLimit
+	}
+}
+
+// WithMaxWait sets how long requests wait for a rate limited provider by
+// default. Zero or negative fails such providers immediately.
+func WithMaxWait(maxWait time.Duration) Option {
+	return func(c *Client) {
+		c.maxWait = max(maxWait, 0)
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+func WithAPIKeys(keys map[string]string) Option {
+	return func(c *Client) {
+		c.keys = staticKeys(keys)
+	}
+}
+
+func WithKeySource(keys KeySource) Option {
+	return func(c *Client) {
+		c.keys = keys
+	}
+}
+
+// WithMemory records successful translations in memory and enables Suggest.
+func WithMemory(memory *Memory) Option {
+	return func(c *Client) {
+		c.memory = memory
+	}
+}
+
+// WithConcurrency bounds how many provider requests the client runs at once,
+// overall and per provider. Zero or negative means unbounded.
+func WithConcurrency(global, perProvider int) Option {
+	return func(c *Client) {
+		c.pool = pool.New(global, perProvider)
+	}
+}
+
+// WithQuota counts usage per provider and skips providers whose quota is
+// used up.
+func WithQuota(quota *Quota) Option {
+	return func(c *Client) {
+		c.quota = quota
+	}
+}
+
+// WithBreaker sets the circuit breaker that stops requests to failing
+// providers. A nil breaker disables it.
+func WithBreaker(breaker *Breaker) Option {
+	return func(c *Client) {
+		c.breaker = breaker
+	}
+}
+
+// WithLatencyTracker sets where request latencies are recorded. A nil tracker
+// disables hedging.
+func WithLatencyTracker(latency *LatencyTracker) Option {
+	return func(c *Client) {
+		c.latency = latency
+	}
+}
+
+// WithHedging turns hedged requests of the chain strategy on or off: when a
+// provider takes longer than its p90 latency, the next one is asked as well
+// and the first answer wins.
+func WithHedging(enabled bool) Option {
+	return func(c *Client) {
+		c.hedging = enabled
+	}
+}
+
+// WithPostProcessing replaces the output cleanup of the listed providers;
+// the others keep DefaultPostProcessing. An empty pipeline returns the output
+// as the provider sent it.
+func WithPostProcessing(pipelines map[string]PostProcessing) Option {
+	return func(c *Client) {
+		c.postProcess = pipelines
+	}
+}
+
+// WithStrategy sets the default strategy and quorum for requests that do not
+// specify their own. Unknown strategies fall back to StrategyAll.
+func WithStrategy(strategy Strategy, quorum int) Option {
+	return func(c *Client) {
+		if parsed, err := ParseStrategy(string(strategy)); err == nil {
+			c.strategy = parsed
+		}
+		if quorum > 0 {
+			c.quorum = quorum
+		}
+	}
+}
+
+// New returns a client using the default providers, an in-memory cache and
+// the default rate limits unless overridden by opts.
+func New(opts ...Option) *Client {
+	c := &Client{
+		providers: DefaultProviders(),
+		cache:     NewCache(),
+		rateLimit: NewRateLimiter(),
+		keys:      staticKeys{},
+		strategy:  StrategyAll,
+		quorum:    DefaultQuorum,
+		maxWait:   DefaultMaxWait,
+		pool:      pool.New(pool.DefaultGlobal, pool.DefaultPerProvider),
+		breaker:   NewBreaker(DefaultBreakerOptions()),
+		latency:   NewLatencyTracker(),
+		hedging:   true,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+func (c *Client) Providers() []Provider {
+	return c.providers
+}
+
+func (c *Client) Cache() *Cache {
+	return c.cache
+}
+
+func (c *Client) RateLimiter() *RateLimiter {
+	return c.rateLimit
+}
+
+func (c *Client) Strategy() Strategy {
+	return c.strategy
+}
+
+func (c *Client) Memory() *Memory {
+	return c.memory
+}
+
+func (c *Client) Quota() *Quota {
+	return c.quota
+}
+
+func (c *Client) Breaker() *Breaker {
+	return c.breaker
+}
+
+func (c *Client) Latency() *LatencyTracker {
+	return c.latency
+}
+
+// Suggest searches the translation memory for segments similar to req.Text
+// without contacting any provider.
+func (c *Client) Suggest(req Request, limit int) []MemoryMatch {
+	if c.memory == nil || strings.TrimSpace(req.Text) == "" {
+		return nil
+	}
+
+	source := languages.Canonical(req.Source)
+	if source == "" || source == AutoDetect {
+		source = utils.DetectFromLanguage(req.Text)
+	}
+	target := utils.DetectToLanguage(source, languages.Canonical(req.Target))
+
+	return c.memory.Search(req.Text, source, target, limit)
+}
+
+// Translate sends req to the selected providers according to its strategy.
+// An error is returned when the strategy could not settle on a translation;
+// the per-provider failures are still available in the response.
+func (c *Client) Translate(ctx context.Context, req Request) (Response, error) {
+	if strings.TrimSpace(req.Text) == "" {
+		return Response{}, ErrEmptyText
+	}
+
+	providers, err := c.selectProviders(req.Providers)
+	if err != nil {
+		return Response{}, err
+	}
+
+	source := languages.Canonical(req.Source)
+	detected := source
+	if source == "" || source == AutoDetect {
+		source = AutoDetect
+		detected = utils.DetectFromLanguage(req.Text)
+	}
+	target := utils.DetectToLanguage(detected, languages.Canonical(req.Target))
+
+	strategy := c.strategy
+	if req.Strategy != "" {
+		if strategy, err = ParseStrategy(string(req.Strategy)); err != nil {
+			return Response{}, err
+		}
+	}
+	quorum := c.quorum
+	if req.Quorum > 0 {
+		quorum = req.Quorum
+	}
+
+	resp := Response{
+		Source:   detected,
+		Target:   target,
+		Strategy: strategy,
+	}
+	maxWait := c.maxWait
+	if req.MaxWait != 0 {
+		maxWait = max(req.MaxWait, 0)
+	}
+
+	resp.Results, resp.Text, err = c.fanOut(ctx, strategy, quorum, providers, job{
+		text:     req.Text,
+		source:   source,
+		detected: detected,
+		target:   target,
+		priority: req.Priority,
+		maxWait:  maxWait,
+		onQueued: req.OnQueued,
+	})
+
+	return resp, err
+}
+
+// Store puts a translation into the cache as if the named provider had
+// returned it. Providers that detect the source language themselves are asked
+// with AutoDetect by default, so the translation is also stored under that key.
+func (c *Client) Store(providerName, text, source, target, translation string) error {
+	if c.cache == nil {
+		return nil
+	}
+
+	providers, err := c.selectProviders([]string{providerName})
+	if err != nil {
+		return err
+	}
+
+	source, target = languages.Canonical(source), languages.Canonical(target)
+	c.cache.Set(cacheKey(providers[0], text, source, target), translation)
+	if source != AutoDetect && utils.SupportsAutoDetect(providerName) {
+		c.cache.Set(cacheKey(providers[0], text, AutoDetect, target), translation)
+	}
+	return nil
+}
+
+// Forget removes the named provider's cached translations of text into
+// target: the one asked with source and its AutoDetect twin, which Store or a
+// request without a source may have filled. It returns how many entries were
+// removed.
+func (c *Client) Forget(providerName, text, source, target string) int {
+	if c.cache == nil {
+		return 0
+	}
+
+	text = cache.NormalizeText(text)
+	source, target = languages.Canonical(source), languages.Canonical(target)
+
+	removed := 0
+	for _, entry := range c.cache.Entries() {
+		if entry.Provider != providerName || entry.Text != text || entry.Target != target {
+			continue
+		}
+		if entry.Source != source && entry.Source != AutoDetect && source != AutoDetect {
+			continue
+		}
+		if c.cache.Delete(entry.Provider, entry.Key) {
+			removed++
+		}
+	}
+	return removed
+}
+
+func (c *Client) selectProviders(names []string) ([]Provider, error) {
+	if len(c.providers) == 0 {
+		return nil, ErrNoProviders
+	}
+	if len(names) == 0 {
+		return c.providers, nil
+	}
+
+	selected := make([]Provider, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, provider := range c.providers {
+			if provider.Name == name {
+				selected = append(selected, provider)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+		}
+	}
+
+	return selected, nil
+}
+
+func (c *Client) translateOne(ctx context.Context, provider Provider, j job) Result {
+	result := Result{Provider: provider.Name}
+	text, source, target := j.text, j.source, j.target
+	// Providers that cannot detect the language get the local guess.
+	if source == AutoDetect && !utils.SupportsAutoDetect(provider.Name) {
+		source = j.detected
+	}
+
+	if err := ctx.Err(); err != nil {
+		result.Err = err
+		return result
+	}
+
+	key := cacheKey(provider, text, source, target)
+	if c.cache != nil {
+		if cached, exists := c.cache.Get(key); exists {
+			result.Text = cached
+			result.Cached = true
+			return result
+		}
+	}
+
+	if RequiresAPIKey(provider.Name) {
+		apiKey := c.keys.GetAPIKey(provider.Name)
+		if apiKey == "" {
+			result.Err = fmt.Errorf("%w: %s", ErrMissingAPIKey, provider.Name)
+			return result
+		}
+		provider = utils.WithAPIKey(provider, apiKey)
+	}
+
+	settled := false
+	if c.breaker != nil {
+		if allowed, retryIn := c.breaker.Allow(provider.Name); !allowed {
+			result.Err = &ServiceError{
+				Service:    provider.Name,
+				ErrorType:  ErrorTypeCircuitOpen,
+				Message:    fmt.Sprintf("Circuit open, retry in %ds", int(math.Ceil(retryIn.Seconds()))),
+				Suggestion: "The provider failed repeatedly; use another provider or wait",
+				RetryAfter: retryIn,
+			}
+			return result
+		}
+		// A probe that ends without an outcome the breaker records, rejected
+		// by the quota or rate limiter, cancelled or failing with an untracked
+		// error type, must not hold the circuit half-open.
+		defer func() {
+			if !settled {
+				c.breaker.Release(provider.Name)
+			}
+		}()
+	}
+
+	if c.quota != nil {
+		if status := c.quota.Status(provider.Name); status.Exhausted() {
+			result.Err = &ServiceError{
+				Service:    provider.Name,
+				ErrorType:  ErrorTypeQuotaExceeded,
+				Message:    fmt.Sprintf("Quota used up (%s)", status.Summary()),
+				Suggestion: "Use another provider or raise the quota in the config file",
+			}
+			return result
+		}
+	}
+
+	var hooks utils.Hooks
+	if c.rateLimit != nil {
+		if err := c.waitForLimiter(ctx, provider.Name, j); err != nil {
+			result.Err = err
+			return result
+		}
+		hooks.RateLimit = func(feedback ratelimit.Feedback) {
+			c.rateLimit.Observe(provider.Name, feedback)
+		}
+	}
+	tokens := 0
+	hooks.Usage = func(used int) {
+		tokens = used
+	}
+
+	release, err := c.pool.Acquire(ctx, provider.Name, j.priority)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer release()
+
+	started := time.Now()
+	trans, err := utils.TranslateObserved(ctx, c.httpClient, provider, text, source, target, hooks)
+	if err != nil {
+		// A cancelled request says nothing about the provider.
+		if c.breaker != nil && ctx.Err() == nil {
+			settled = c.breaker.Failure(provider.Name, utils.CreateServiceError(provider.Name, err, 0).ErrorType)
+		}
+		result.Err = err
+		return result
+	}
+	took := time.Since(started)
+
+	trans = c.postProcessing(provider.Name).Apply(text, trans)
+	if strings.TrimSpace(trans) == "" {
+		if c.breaker != nil {
+			settled = c.breaker.Failure(provider.Name, ErrorTypeInvalidResponse)
+		}
+		result.Err = &ServiceError{
+			Service:     provider.Name,
+			ErrorType:   ErrorTypeInvalidResponse,
+			Message:     "Empty translation",
+			Suggestion:  "Try another service",
+			IsRetryable: true,
+		}
+		return result
+	}
+	// Only a usable translation counts as the provider working.
+	if c.breaker != nil {
+		settled = true
+		c.breaker.Success(provider.Name)
+	}
+	if c.latency != nil {
+		c.latency.Observe(provider.Name, took)
+	}
+
+	var warnings []string
+	if c.quota != nil {
+		status, crossed, err := c.quota.Record(provider.Name, len([]rune(text)), tokens)
+		if err != nil {
+			warnings = append(warnings, err.Error())
+		}
+		if crossed {
+			warnings = append(warnings, fmt.Sprintf("%s quota almost used up: %s", provider.Name, status.Summary()))
+		}
+	}
+
+	// Doubtful translations are shown, but not kept for later requests.
+	result.Suspect = utils.CheckTranslation(text, trans, j.detected, target)
+	if result.Suspect != "" {
+		warnings = append(warnings, fmt.Sprintf("%s: %s", provider.Name, result.Suspect))
+	}
+	result.Warning = strings.Join(warnings, "; ")
+
+	if c.cache != nil && result.Suspect == "" {
+		c.cache.Set(key, trans)
+	}
+	if c.memory != nil && result.Suspect == "" {
+		// Segments are searched by source language, so an uncertain
+		// detection stores the best guess rather than "auto".
+		sourceLang := j.detected
+		if sourceLang == AutoDetect {
+			sourceLang, _ = detect.Best(text)
+		}
+		if sourceLang != "" {
+			_, _ = c.memory.Add(Segment{
+				Source:     text,
+				Target:     trans,
+				SourceLang: sourceLang,
+				TargetLang: target,
+				Provider:   provider.Name,
+			})
+		}
+	}
+
+	result.Text = trans
+	return result
+}
+
+func (c *Client) postProcessing(providerName string) PostProcessing {
+	if pipeline, exists := c.postProcess[providerName]; exists {
+		return pipeline
+	}
+	return DefaultPostProcessing(providerName)
+}
+
+// waitForLimiter holds the request until the provider's limiter lets it
+// through, or fails if that would take longer than the job's maximum wait.
+func (c *Client) waitForLimiter(ctx context.Context, providerName string, j job) error {
+	deadline := time.Now().Add(j.maxWait)
+	for {
+		wait := c.rateLimit.Reserve(providerName, len([]rune(j.text)))
+		if wait == 0 {
+			return nil
+		}
+
+		start := time.Now().Add(wait)
+		if start.After(deadline) {
+			return &ServiceError{
+				Service:     providerName,
+				ErrorType:   ErrorTypeRateLimit,
+				Message:     fmt.Sprintf("Rate limit exceeded, next request possible in %s", wait.Round(time.Second)),
+				Suggestion:  "Wait before making more requests or allow a longer wait",
+				IsRetryable: true,
+				RetryAfter:  wait,
+			}
+		}
+
+		if j.onQueued != nil {
+			j.onQueued(providerName, start)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func cacheKey(provider Provider, text, source, target string) CacheKey {
+	key := CacheKey{
+		Provider: provider.Name,
+		Text:     text,
+		Source:   source,
+		Target:   target,
+		Model:    utils.ProviderModel(provider.Name),
+		Options:  map[string]string{"url": provider.URL},
+	}
+	if key.Model != "" {
+		key.PromptVersion = utils.PromptVersion
+	}
+	return key
+}
@@ -0,0 +1,131 @@
+package translate
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"translatego/internal/breaker"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func respond(status int, body string) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: status,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    r,
+		}, nil
+	})}
+}
+
+// A half-open probe that fails with an error type the breaker does not track
+// must free the probe slot rather than block the provider for ProbeTimeout.
+func TestProbeEndingInUntrackedErrorIsReleased(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantState breaker.State
+	}{
+		{"untracked error releases the probe", http.StatusTooManyRequests, breaker.HalfOpen},
+		{"tracked error reopens the circuit", http.StatusInternalServerError, breaker.Open},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			circuits := NewBreaker(BreakerOptions{
+				FailureThreshold: 1,
+				Cooldown:         20 * time.Millisecond,
+				ProbeTimeout:     time.Minute,
+				ErrorTypes:       map[string]bool{"SERVER_ERROR": true},
+			})
+			circuits.Failure("GOOGLE", "SERVER_ERROR")
+			time.Sleep(30 * time.Millisecond)
+
+			client := New(
+				WithBreaker(circuits),
+				WithHTTPClient(respond(tt.status, "")),
+				WithCache(nil),
+				WithRateLimiter(nil),
+				WithMemory(nil),
+				WithHedging(false),
+			)
+			if _, err := client.Translate(context.Background(), Request{
+				Text: "Hello", Source: "en", Target: "de", Providers: []string{"GOOGLE"},
+			}); err == nil {
+				t.Fatal("probe succeeded, want an error")
+			}
+
+			state, retryIn := circuits.State("GOOGLE")
+			if state != tt.wantState {
+				t.Errorf("state = %s, want %s", state, tt.wantState)
+			}
+			if state == breaker.HalfOpen && retryIn > 0 {
+				t.Errorf("probe slot held for %v, want it released", retryIn)
+			}
+		})
+	}
+}
+
+func TestForgetRemovesAutoDetectTwin(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"explicit source", "en"},
+		{"auto source", AutoDetect},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := New()
+			if err := client.Store("GOOGLE", "Good  morning", "en", "de", "Guten Morgen"); err != nil {
+				t.Fatal(err)
+			}
+			if err := client.Store("GOOGLE", "Good morning", "en", "fr", "Bonjour"); err != nil {
+				t.Fatal(err)
+			}
+
+			if removed := client.Forget("GOOGLE", "Good morning", tt.source, "de"); removed != 2 {
+				t.Errorf("Forget removed %d entries, want 2", removed)
+			}
+			if size := client.Cache().Size(); size != 2 {
+				t.Errorf("cache holds %d entries, want the 2 French ones", size)
+			}
+		})
+	}
+}
+
+// Text too short for a confident detection is stored under the best guess,
+// so that a search with an explicit source language finds it again.
+func TestMemoryStoresGuessedSource(t *testing.T) {
+	memory := NewMemory(0)
+	client := New(
+		WithHTTPClient(respond(http.StatusOK, `{"data":"Bye"}`)),
+		WithCache(nil),
+		WithRateLimiter(nil),
+		WithMemory(memory),
+	)
+	if _, err := client.Translate(context.Background(), Request{
+		Text: "Tschüss", Source: AutoDetect, Target: "en", Providers: []string{"DEEPL"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	segments := memory.Segments()
+	if len(segments) != 1 || segments[0].SourceLang != "de" {
+		t.Fatalf("segments = %+v, want one with source de", segments)
+	}
+	if matches := memory.Search("Tschüss", "de", "en", 1); len(matches) != 1 {
+		t.Errorf("search with source de found %d segments, want 1", len(matches))
+	}
+}
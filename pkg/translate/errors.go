@@ -0,0 +1,63 @@
+package translate
+
+import (
+	"errors"
+
+	"translatego/internal/utils"
+)
+
+// ServiceError describes a failed call to a single provider.
+type ServiceError = utils.ServiceError
+
+const (
+	ErrorTypeTimeout         = utils.ErrorTypeTimeout
+	ErrorTypeRateLimit       = utils.ErrorTypeRateLimit
+	ErrorTypeUnauthorized    = utils.ErrorTypeUnauthorized
+	ErrorTypeForbidden       = utils.ErrorTypeForbidden
+	ErrorTypeNotFound        = utils.ErrorTypeNotFound
+	ErrorTypeServerError     = utils.ErrorTypeServerError
+	ErrorTypeServiceDown     = utils.ErrorTypeServiceDown
+	ErrorTypeNetworkError    = utils.ErrorTypeNetworkError
+	ErrorTypeLanguageError   = utils.ErrorTypeLanguageError
+	ErrorTypeQuotaExceeded   = utils.ErrorTypeQuotaExceeded
+	ErrorTypeCircuitOpen     = utils.ErrorTypeCircuitOpen
+	ErrorTypeInvalidResponse = utils.ErrorTypeInvalidResponse
+	ErrorTypeUnknown         = utils.ErrorTypeUnknown
+)
+
+var (
+	ErrEmptyText          = errors.New("translate: empty text")
+	ErrNoProviders        = errors.New("translate: no providers configured")
+	ErrUnknownProvider    = errors.New("translate: unknown provider")
+	ErrMissingAPIKey      = errors.New("translate: missing API key")
+	ErrAllProvidersFailed = errors.New("translate: all providers failed")
+	ErrUnknownStrategy    = errors.New("translate: unknown strategy")
+	ErrNoQuorum           = errors.New("translate: providers did not reach a quorum")
+	ErrSkipped            = errors.New("translate: skipped by strategy")
+)
+
+// Errors matched by a ServiceError of the corresponding ErrorType, for use
+// with errors.Is.
+var (
+	ErrTimeout         = utils.ErrTimeout
+	ErrRateLimited     = utils.ErrRateLimited
+	ErrUnauthorized    = utils.ErrUnauthorized
+	ErrForbidden       = utils.ErrForbidden
+	ErrNotFound        = utils.ErrNotFound
+	ErrServerError     = utils.ErrServerError
+	ErrServiceDown     = utils.ErrServiceDown
+	ErrNetwork         = utils.ErrNetwork
+	ErrLanguage        = utils.ErrLanguage
+	ErrQuotaExceeded   = utils.ErrQuotaExceeded
+	ErrCircuitOpen     = utils.ErrCircuitOpen
+	ErrInvalidResponse = utils.ErrInvalidResponse
+)
+
+// AsServiceError reports whether err carries a *ServiceError and returns it.
+func AsServiceError(err error) (*ServiceError, bool) {
+	var serviceErr *ServiceError
+	if errors.As(err, &serviceErr) {
+		return serviceErr, true
+	}
+	return nil, false
+}
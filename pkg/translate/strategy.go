@@ -0,0 +1,249 @@
+package translate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Strategy decides how a request is spread over the selected providers.
+type Strategy string
+
+const (
+	// StrategyAll asks every provider and waits for all of them.
+	StrategyAll Strategy = "all"
+	// StrategyFirst asks every provider and keeps the first success.
+	StrategyFirst Strategy = "first"
+	// StrategyChain asks providers one at a time, in order, until one succeeds,
+	// hedging slow providers with the next one.
+	StrategyChain Strategy = "chain"
+	// StrategyQuorum asks every provider and stops once enough of them agree.
+	StrategyQuorum Strategy = "quorum"
+)
+
+const DefaultQuorum = 2
+
+func Strategies() []Strategy {
+	return []Strategy{StrategyAll, StrategyFirst, StrategyChain, StrategyQuorum}
+}
+
+func ParseStrategy(s string) (Strategy, error) {
+	if s == "" {
+		return StrategyAll, nil
+	}
+
+	for _, strategy := range Strategies() {
+		if string(strategy) == strings.ToLower(s) {
+			return strategy, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownStrategy, s)
+}
+
+// SingleResult reports whether the strategy stops after settling on one
+// translation.
+func (s Strategy) SingleResult() bool {
+	return s == StrategyFirst || s == StrategyChain || s == StrategyQuorum
+}
+
+func (c *Client) fanOut(ctx context.Context, strategy Strategy, quorum int, providers []Provider, j job) ([]Result, string, error) {
+	switch strategy {
+	case StrategyFirst:
+		results, chosen, _ := c.fanOutUntil(ctx, providers, j, func(results []Result, latest Result) (string, bool) {
+			return latest.Text, latest.Err == nil
+		})
+		return results, chosen, collectErrors(results)
+	case StrategyChain:
+		return c.chain(ctx, providers, j)
+	case StrategyQuorum:
+		if quorum <= 0 {
+			quorum = DefaultQuorum
+		}
+		results, chosen, settled := c.fanOutUntil(ctx, providers, j, func(results []Result, latest Result) (string, bool) {
+			if latest.Err != nil {
+				return "", false
+			}
+			agreeing := 0
+			for _, result := range results {
+				if result.Err == nil && result.Provider != "" && sameTranslation(result.Text, latest.Text) {
+					agreeing++
+				}
+			}
+			return latest.Text, agreeing >= quorum
+		})
+		if settled {
+			return results, chosen, nil
+		}
+		if err := collectErrors(results); err != nil {
+			return results, "", err
+		}
+		return results, "", fmt.Errorf("%w: %d of %d providers agreed", ErrNoQuorum, largestAgreement(results), quorum)
+	default:
+		results, _, _ := c.fanOutUntil(ctx, providers, j, func(results []Result, latest Result) (string, bool) {
+			return "", false
+		})
+		return results, firstSuccess(results), collectErrors(results)
+	}
+}
+
+// fanOutUntil runs all providers concurrently and stops as soon as done
+// reports true, cancelling the providers that are still running.
+func (c *Client) fanOutUntil(ctx context.Context, providers []Provider, j job, done func(results []Result, latest Result) (string, bool)) ([]Result, string, bool) {
+	fanCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type indexed struct {
+		index  int
+		result Result
+	}
+
+	ch := make(chan indexed, len(providers))
+	for i, provider := range providers {
+		go func(i int, provider Provider) {
+			ch <- indexed{index: i, result: c.translateOne(fanCtx, provider, j)}
+		}(i, provider)
+	}
+
+	results := make([]Result, len(providers))
+	var chosen string
+	settled := false
+	for range providers {
+		item := <-ch
+		results[item.index] = item.result
+		if settled {
+			continue
+		}
+		if winner, ok := done(results, item.result); ok {
+			chosen = winner
+			settled = true
+			cancel()
+		}
+	}
+
+	for i := range results {
+		if settled && errors.Is(results[i].Err, context.Canceled) && ctx.Err() == nil {
+			results[i].Err = ErrSkipped
+		}
+	}
+
+	return results, chosen, settled
+}
+
+// hedgePercentile is the latency after which a chained provider is hedged.
+const hedgePercentile = 0.9
+
+// chain asks providers in order until one succeeds. With hedging, a provider
+// that has not answered within its p90 latency gets the next one started
+// alongside it; the first success wins and the others are cancelled.
+func (c *Client) chain(ctx context.Context, providers []Provider, j job) ([]Result, string, error) {
+	chainCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type indexed struct {
+		index  int
+		result Result
+	}
+
+	results := make([]Result, len(providers))
+	finished := make([]bool, len(providers))
+	ch := make(chan indexed, len(providers))
+	next, running := 0, 0
+	var hedge <-chan time.Time
+
+	startNext := func() {
+		i := next
+		next++
+		running++
+		go func() {
+			ch <- indexed{index: i, result: c.translateOne(chainCtx, providers[i], j)}
+		}()
+
+		hedge = nil
+		if delay, ok := c.hedgeDelay(providers[i].Name); ok && next < len(providers) {
+			hedge = time.After(delay)
+		}
+	}
+
+	startNext()
+	for running > 0 {
+		select {
+		case item := <-ch:
+			running--
+			results[item.index] = item.result
+			finished[item.index] = true
+			if item.result.Err == nil {
+				for i := range providers {
+					if !finished[i] {
+						results[i] = Result{Provider: providers[i].Name, Err: ErrSkipped}
+					}
+				}
+				return results, item.result.Text, nil
+			}
+			if running == 0 && next < len(providers) {
+				startNext()
+			}
+		case <-hedge:
+			startNext()
+		}
+	}
+
+	return results, "", collectErrors(results)
+}
+
+func (c *Client) hedgeDelay(providerName string) (time.Duration, bool) {
+	if !c.hedging || c.latency == nil {
+		return 0, false
+	}
+	return c.latency.Percentile(providerName, hedgePercentile)
+}
+
+func collectErrors(results []Result) error {
+	var errs []error
+	for _, result := range results {
+		if result.Err == nil {
+			return nil
+		}
+		errs = append(errs, result.Err)
+	}
+
+	return fmt.Errorf("%w: %w", ErrAllProvidersFailed, errors.Join(errs...))
+}
+
+func largestAgreement(results []Result) int {
+	largest := 0
+	for _, a := range results {
+		if a.Err != nil {
+			continue
+		}
+		agreeing := 0
+		for _, b := range results {
+			if b.Err == nil && sameTranslation(a.Text, b.Text) {
+				agreeing++
+			}
+		}
+		if agreeing > largest {
+			largest = agreeing
+		}
+	}
+	return largest
+}
+
+func firstSuccess(results []Result) string {
+	for _, result := range results {
+		if result.Err == nil && result.Provider != "" {
+			return result.Text
+		}
+	}
+	return ""
+}
+
+func sameTranslation(a, b string) bool {
+	normalize := func(s string) string {
+		s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+		return strings.TrimRight(s, ".!?。")
+	}
+	return normalize(a) == normalize(b)
+}
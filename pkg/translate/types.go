@@ -0,0 +1,247 @@
+package translate
+
+import (
+	"time"
+
+	"translatego/internal/breaker"
+	"translatego/internal/cache"
+	"translatego/internal/config"
+	"translatego/internal/detect"
+	"translatego/internal/languages"
+	"translatego/internal/latency"
+	"translatego/internal/memory"
+	"translatego/internal/pool"
+	"translatego/internal/postprocess"
+	"translatego/internal/quota"
+	"translatego/internal/ratelimit"
+	"translatego/internal/utils"
+)
+
+// Provider is the endpoint description of a single translation service.
+type Provider = utils.ServiceConfig
+
+// Cache stores successful translations per provider.
+type Cache = cache.Manager
+
+// CacheKey identifies a cached translation by provider, normalized text,
+// languages, model, prompt version and provider options.
+type CacheKey = cache.Key
+
+// CacheEntry is a cached translation as listed by Cache.Entries.
+type CacheEntry = cache.Entry
+
+// CacheOptions bounds a cache by entries, bytes and age.
+type CacheOptions = cache.Options
+
+// CacheStats reports cache occupancy and hit, miss and eviction counters.
+type CacheStats = cache.Stats
+
+// Memory is a translation memory searched by fuzzy similarity.
+type Memory = memory.Memory
+
+// Segment is a source/target pair stored in a Memory.
+type Segment = memory.Segment
+
+// MemoryMatch is a Segment with its similarity to the query, from 0 to 1.
+type MemoryMatch = memory.Match
+
+// RateLimiter throttles requests per provider.
+type RateLimiter = ratelimit.Manager
+
+// RateLimits are the token bucket limits of one provider.
+type RateLimits = ratelimit.Limits
+
+// Quota tracks daily and monthly usage per provider.
+type Quota = quota.Tracker
+
+// QuotaLimits are the daily and monthly caps of one provider.
+type QuotaLimits = quota.Limits
+
+// QuotaStatus is the usage of one provider against its QuotaLimits.
+type QuotaStatus = quota.Status
+
+// Breaker stops sending requests to providers that keep failing.
+type Breaker = breaker.Breaker
+
+// BreakerOptions decide when a provider's circuit opens and for how long.
+type BreakerOptions = breaker.Options
+
+// LatencyTracker records recent request latencies per provider.
+type LatencyTracker = latency.Tracker
+
+// PostProcessing is the cleanup applied to a provider's output before it is
+// returned, cached or compared.
+type PostProcessing = postprocess.Pipeline
+
+// LanguageCandidate is a possible language of a text with the confidence in
+// it, from 0 to 1.
+type LanguageCandidate = detect.Candidate
+
+// Priority decides which waiting requests get a free connection first.
+type Priority = pool.Priority
+
+const (
+	// PriorityInteractive is for requests a user is waiting on (default).
+	PriorityInteractive = pool.PriorityInteractive
+	// PriorityBatch is for background jobs such as cache warm-up.
+	PriorityBatch = pool.PriorityBatch
+)
+
+// Request is a single translation request.
+//
+// Source may be left empty or set to AutoDetect to detect the language from
+// Text; Response.Source then holds the local guess. Providers restricts the
+// request to the named providers, in that order; all configured providers are
+// used when it is empty. Strategy and Quorum fall back to the client defaults
+// when unset.
+//
+// A provider held back by its rate limiter waits up to MaxWait (the client
+// default when zero, not at all when negative) and OnQueued, if set, is told
+// when it is expected to start.
+type Request struct {
+	Text      string
+	Source    string
+	Target    string
+	Providers []string
+	Strategy  Strategy
+	Quorum    int
+	Priority  Priority
+	MaxWait   time.Duration
+	OnQueued  func(provider string, start time.Time)
+}
+
+// Result is the outcome of a request against one provider.
+//
+// Warning is set on successful results that need the user's attention, such
+// as a provider nearing its quota. Suspect says why a successful translation
+// looks untranslated, such as being identical to the input; suspect
+// translations are neither cached nor added to the translation memory.
+type Result struct {
+	Provider string
+	Text     string
+	Cached   bool
+	Warning  string
+	Suspect  string
+	Err      error
+}
+
+// Response holds the per-provider results in the order providers were asked.
+// Text is the translation the strategy settled on.
+type Response struct {
+	Source   string
+	Target   string
+	Text     string
+	Strategy Strategy
+	Results  []Result
+}
+
+// Successful returns the results that produced a translation.
+func (r Response) Successful() []Result {
+	var results []Result
+	for _, result := range r.Results {
+		if result.Err == nil {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// KeySource resolves API keys by provider name. *config.Manager satisfies it.
+type KeySource interface {
+	GetAPIKey(providerName string) string
+}
+
+type staticKeys map[string]string
+
+func (k staticKeys) GetAPIKey(providerName string) string {
+	return k[providerName]
+}
+
+func DefaultProviders() []Provider {
+	return config.GetAvailableServices()
+}
+
+func NewCache() *Cache {
+	return cache.NewManager()
+}
+
+func NewCacheWithOptions(options CacheOptions) *Cache {
+	return cache.NewManagerWithOptions(options)
+}
+
+// NewMemory returns an in-memory translation memory that reports matches at
+// or above threshold.
+func NewMemory(threshold float64) *Memory {
+	return memory.New(threshold)
+}
+
+// NewRateLimiter returns a limiter using DefaultRateLimits; use SetLimits to
+// change the limits of a provider.
+func NewRateLimiter() *RateLimiter {
+	return ratelimit.NewManager()
+}
+
+// NewQuota returns an in-memory quota tracker; providers missing from limits
+// use their free tier quotas.
+func NewQuota(limits map[string]QuotaLimits) *Quota {
+	return quota.New(limits)
+}
+
+// NewBreaker returns an in-memory circuit breaker.
+func NewBreaker(options BreakerOptions) *Breaker {
+	return breaker.New(options)
+}
+
+func DefaultBreakerOptions() BreakerOptions {
+	return breaker.DefaultOptions()
+}
+
+// NewLatencyTracker returns an in-memory latency tracker.
+func NewLatencyTracker() *LatencyTracker {
+	return latency.New()
+}
+
+// DefaultPostProcessing returns the cleanup a provider's output gets unless
+// WithPostProcessing overrides it.
+func DefaultPostProcessing(providerName string) PostProcessing {
+	return postprocess.DefaultPipeline(providerName)
+}
+
+// ParsePostProcessing builds a pipeline from step names such as "entities",
+// "plus", "preamble", "quotes", "whitespace" and "nfc".
+func ParsePostProcessing(steps []string) (PostProcessing, error) {
+	return postprocess.Parse(steps)
+}
+
+// DetectLanguage guesses the language of text offline and returns the
+// candidates, most likely first.
+func DetectLanguage(text string) []LanguageCandidate {
+	return detect.Detect(text)
+}
+
+func DefaultRateLimits(providerName string) RateLimits {
+	return ratelimit.DefaultLimits(providerName)
+}
+
+// Language is a registry entry: a BCP-47 tag with its names, ISO 639 codes,
+// script and writing direction.
+type Language = languages.Language
+
+// Languages returns every language the registry knows, in display order.
+func Languages() []Language {
+	return languages.All()
+}
+
+// LookupLanguage returns the registry entry for a BCP-47 tag, falling back to
+// its base language.
+func LookupLanguage(tag string) (Language, bool) {
+	return languages.Lookup(tag)
+}
+
+// AutoDetect as Request.Source lets providers that can detect the source
+// language do so; the others get a local guess.
+const AutoDetect = utils.AutoDetect
+
+func RequiresAPIKey(providerName string) bool {
+	return providerName == "OPENAI" || providerName == "OPENROUTER"
+}